@@ -69,16 +69,16 @@ func Base(dbPath, baseFileDir string) error {
 
 	//read base.dbf
 	dbfPath := filepath.Join(baseFileDir, "base.dbf")
-	recs, err := tdx.ParseBaseDbf(dbfPath)
+	dbf, err := tdx.ParseBaseDbf(dbfPath)
 	if err != nil {
 		return fmt.Errorf("failed to parse file %s: %w", dbfPath, err)
 	}
 
-	err = database.ImportBase(db, recs)
+	err = database.ImportBase(db, dbf)
 	if err != nil {
 		return fmt.Errorf("failed to import base file %w", err)
 	}
-	fmt.Printf("✅ 已导入base数据%s\n", dbfPath)
+	fmt.Printf("✅ 已导入base数据%s 快照日期 %s\n", dbfPath, dbf.Date.Format("2006-01-02"))
 
 	blockFilter := make(map[string]*tdx.BlockData)
 	database.CheckBlocks(db)
@@ -170,9 +170,31 @@ func Base(dbPath, baseFileDir string) error {
 	}
 
 	//------------------tnf file------------------
-	tdx.ReadTnfRecords(baseFileDir, func(*tdx.StockRecord, string) {
-
+	var srecs []database.SecurityRecord
+	err = tdx.ReadTnfRecords(baseFileDir, func(r *tdx.StockRecord, subtype string) {
+		srecs = append(srecs, database.SecurityRecord{StockRecord: r, Subtype: subtype})
 	})
+	if err != nil {
+		return fmt.Errorf("failed to read tnf files in %s: %w", baseFileDir, err)
+	}
+
+	err = database.ImportSecurity(db, srecs)
+	if err != nil {
+		return fmt.Errorf("failed to import security file %w", err)
+	}
+	fmt.Printf("✅ 已导入证券信息%d条\n", len(srecs))
+
+	if err := database.ImportTnfNameEvents(db); err != nil {
+		return fmt.Errorf("failed to import security name events: %w", err)
+	}
+	fmt.Printf("🔄 更新证券名称历史 (%s)\n", database.SecurityNameDailyViewName)
+	if err := database.RebuildSecurityNameHistory(db); err != nil {
+		return fmt.Errorf("failed to rebuild security name history: %w", err)
+	}
+
+	if err := UpdateSymbolMap(db); err != nil {
+		return fmt.Errorf("failed to update symbol map: %w", err)
+	}
 
 	return nil
 }
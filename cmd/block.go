@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jing2uo/tdx2db/database"
+	"github.com/jing2uo/tdx2db/model"
+	"github.com/jing2uo/tdx2db/tdx"
+)
+
+type BlockOptions struct {
+	DbPath   string
+	Query    string // SQL 查询，取第一列作为证券代码
+	ListPath string // 代码列表文件，每行一个，# 开头为注释
+	Name     string // 板块名称，写 .dat 时使用
+	Output   string // .blk 生成自定义板块文件，其余按 block*.dat 格式生成
+}
+
+// Block 将查询结果或代码列表生成通达信板块文件
+func Block(opts BlockOptions) error {
+	var symbols []string
+	var err error
+	if opts.Query != "" {
+		symbols, err = querySymbols(opts.DbPath, opts.Query)
+	} else {
+		symbols, err = readSymbolList(opts.ListPath)
+	}
+	if err != nil {
+		return err
+	}
+	if len(symbols) == 0 {
+		return fmt.Errorf("no symbols found")
+	}
+	fmt.Printf("📋 共 %d 只证券\n", len(symbols))
+
+	if strings.EqualFold(filepath.Ext(opts.Output), ".blk") {
+		if err := tdx.WriteBlk(opts.Output, symbols); err != nil {
+			return fmt.Errorf("failed to write %s: %w", opts.Output, err)
+		}
+		fmt.Printf("✅ 已生成自定义板块文件 %s\n", opts.Output)
+		return nil
+	}
+
+	if opts.Name == "" {
+		return fmt.Errorf("block name cannot be empty")
+	}
+
+	codes := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		codes[i] = symbol[2:]
+	}
+	block := &tdx.BlockData{Name: opts.Name, Count: uint16(len(codes)), Codes: codes}
+
+	//已有文件时替换同名板块，保留其他板块
+	blocks := []*tdx.BlockData{block}
+	if _, err := os.Stat(opts.Output); err == nil {
+		old, err := tdx.ReadBlock(opts.Output)
+		if err != nil {
+			return fmt.Errorf("failed to read block file %s: %w", opts.Output, err)
+		}
+		blocks = blocks[:0]
+		replaced := false
+		for _, b := range old {
+			if b.Name == opts.Name {
+				b = block
+				replaced = true
+			}
+			blocks = append(blocks, b)
+		}
+		if !replaced {
+			blocks = append(blocks, block)
+		}
+	}
+
+	if err := tdx.WriteBlock(opts.Output, blocks); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.Output, err)
+	}
+	fmt.Printf("✅ 已写入板块 %s 到 %s\n", opts.Name, opts.Output)
+	return nil
+}
+
+func querySymbols(dbPath, query string) ([]string, error) {
+	if dbPath == "" {
+		return nil, fmt.Errorf("database path cannot be empty")
+	}
+	db, err := database.Connect(model.DBConfig{Path: dbPath})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run query: %w", err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query columns: %w", err)
+	}
+
+	var raw []string
+	values := make([]any, len(cols))
+	for rows.Next() {
+		var symbol string
+		values[0] = &symbol
+		for i := 1; i < len(cols); i++ {
+			values[i] = new(any)
+		}
+		if err := rows.Scan(values...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		raw = append(raw, symbol)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return normalizeSymbols(raw)
+}
+
+func readSymbolList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var raw []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		raw = append(raw, strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return normalizeSymbols(raw)
+}
+
+// normalizeSymbols 统一为 sh600000 形式并去重，纯 6 位代码按代码段推断市场
+func normalizeSymbols(raw []string) ([]string, error) {
+	seen := make(map[string]bool, len(raw))
+	res := make([]string, 0, len(raw))
+	for _, s := range raw {
+		symbol := strings.ToLower(strings.TrimSpace(s))
+		if len(symbol) == 6 {
+			switch {
+			case strings.HasPrefix(symbol, "92") || symbol[0] == '4' || symbol[0] == '8':
+				symbol = "bj" + symbol
+			case symbol[0] == '5' || symbol[0] == '6' || symbol[0] == '9':
+				symbol = "sh" + symbol
+			default:
+				symbol = "sz" + symbol
+			}
+		}
+		if len(symbol) != 8 || (symbol[:2] != "sh" && symbol[:2] != "sz" && symbol[:2] != "bj") {
+			return nil, fmt.Errorf("invalid symbol %q", s)
+		}
+		if seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		res = append(res, symbol)
+	}
+	return res, nil
+}
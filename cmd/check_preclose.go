@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jing2uo/tdx2db/database"
+	"github.com/jing2uo/tdx2db/model"
+)
+
+// CheckPreClose 对比 TNF 前收盘价与计算的 pre_close，结果写入 raw_preclose_check；
+// fail 为 true 时存在不一致、缺少前收盘价或没有可对比的记录都返回错误，供定时任务以非零状态退出
+func CheckPreClose(dbPath string, tolerance float64, fail bool) error {
+	if dbPath == "" {
+		return fmt.Errorf("database path cannot be empty")
+	}
+	dbConfig := model.DBConfig{Path: dbPath}
+	db, err := database.Connect(dbConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	fmt.Printf("🔍 对比 TNF 前收盘价与计算的前收盘价 (容差 %g)\n", tolerance)
+	res, err := database.CheckPreClose(db, tolerance)
+	if err != nil {
+		return err
+	}
+
+	if res.Checked == 0 {
+		fmt.Println("⚠️ 没有可对比的记录，TNF 行情日期可能晚于日线最新日期")
+		if fail {
+			return fmt.Errorf("no symbol to check, TNF date has no daily data")
+		}
+		return nil
+	}
+
+	fmt.Printf("📊 共对比 %d 只，不一致 %d 只，缺少前收盘价 %d 只\n", res.Checked, len(res.Mismatches), len(res.Missing))
+	if len(res.Mismatches) == 0 && len(res.Missing) == 0 {
+		fmt.Println("✅ 前收盘价一致")
+		return nil
+	}
+
+	if len(res.Mismatches) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "symbol\tdate\ttdx_prev_close\tpre_close\tdiff\thas_event")
+		for _, m := range res.Mismatches {
+			fmt.Fprintf(w, "%s\t%s\t%.3f\t%.4f\t%.4f\t%t\n",
+				m.Symbol, m.Date.Format("2006-01-02"), m.TdxPrevClose, m.PreClose, m.Diff, m.HasEvent)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(res.Missing) > 0 {
+		fmt.Printf("⚠️ 当日有日线但没有计算出前收盘价，需重新运行 cron: %s\n", strings.Join(res.Missing, ", "))
+	}
+	fmt.Printf("⚠️ 明细见表 %s\n", database.PreCloseCheckSchema.Name)
+
+	if fail {
+		return fmt.Errorf("%d symbols have pre_close mismatch, %d symbols have no computed pre_close", len(res.Mismatches), len(res.Missing))
+	}
+	return nil
+}
@@ -21,6 +21,17 @@ var ValidPrefixes = []string{
 	"sh60",     // 上证主板
 	"sh68",     // 科创板
 	"bj920",    // 北证
+	"sh51",     // 沪市 ETF
+	"sh56",     // 沪市 ETF
+	"sh58",     // 沪市科创板 ETF
+	"sh501",    // 沪市 LOF
+	"sh506",    // 沪市 LOF
+	"sz15",     // 深市 ETF
+	"sz16",     // 深市 LOF
+	"sh11",     // 沪市可转债
+	"sz12",     // 深市可转债
+	"sh900",    // 沪市 B 股
+	"sz20",     // 深市 B 股
 	"sh000300", // 沪深300
 	"sh000905", // 中证500
 	"sh000852", // 中证1000
@@ -16,6 +16,7 @@ type ConvertOptions struct {
 	InputPath  string
 	InputType  InputSourceType
 	OutputPath string
+	Datatool   bool // 四代压缩包改用 datatool 转档，原生解码结果有疑问时用于对照
 }
 
 const (
@@ -58,8 +59,6 @@ func Convert(opts ConvertOptions) error {
 		}
 	}
 
-	dataDir := DataDir
-
 	var validPrefixes = []string{"sh", "sz", "bj"}
 
 	switch opts.InputType {
@@ -81,7 +80,7 @@ func Convert(opts ConvertOptions) error {
 		output := filepath.Join(opts.OutputPath, "tdx2db_1min.csv")
 
 		fmt.Println("🐢 开始转换 1 分钟数据")
-		_, err := tdx.ConvertFiles2Csv(opts.InputPath, validPrefixes, output, ".01")
+		_, err := tdx.ConvertFiles2Csv(opts.InputPath, validPrefixes, output, ".01", ".lc1")
 		if err != nil {
 			return fmt.Errorf("failed to convert 1min files: %w", err)
 		}
@@ -93,7 +92,7 @@ func Convert(opts ConvertOptions) error {
 		output := filepath.Join(opts.OutputPath, "tdx2db_5min.csv")
 
 		fmt.Println("🐢 开始转换 5 分钟数据")
-		_, err := tdx.ConvertFiles2Csv(opts.InputPath, validPrefixes, output, ".5")
+		_, err := tdx.ConvertFiles2Csv(opts.InputPath, validPrefixes, output, ".5", ".lc5")
 		if err != nil {
 			return fmt.Errorf("failed to convert 5min files: %w", err)
 		}
@@ -106,35 +105,18 @@ func Convert(opts ConvertOptions) error {
 		filename := filepath.Base(opts.InputPath)
 		baseName := filename[:len(filename)-len(filepath.Ext(filename))]
 
-		targetPath := filepath.Join(VipdocDir, "newdatetick")
-		if err := os.MkdirAll(targetPath, 0755); err != nil {
-			return fmt.Errorf("failed to create directory: %w", err)
-		}
-		if err := utils.UnzipFile(opts.InputPath, targetPath); err != nil {
-			return fmt.Errorf("failed to unzip file %s: %w", opts.InputPath, err)
-		}
-
-		fmt.Printf("🐢 开始转档分笔数据\n")
-		if err := tdx.DatatoolCreate(dataDir, "tick", Today); err != nil {
-			return fmt.Errorf("failed to execute DatatoolTickCreate: %w", err)
-		}
-		if err := tdx.DatatoolCreate(dataDir, "min", Today); err != nil {
-			return fmt.Errorf("failed to execute DatatoolMinCreate: %w", err)
-		}
-
 		min1_output := filepath.Join(opts.OutputPath, fmt.Sprintf("%s_1min.csv", baseName))
 		min5_output := filepath.Join(opts.OutputPath, fmt.Sprintf("%s_5min.csv", baseName))
 
-		fmt.Printf("🐢 开始转换 1 分钟数据\n")
-		_, err := tdx.ConvertFiles2Csv(VipdocDir, validPrefixes, min1_output, ".01")
-		if err != nil {
-			return fmt.Errorf("failed to convert 1-minute files: %w", err)
-		}
-
-		fmt.Printf("🐢 开始转换 5 分钟数据\n")
-		_, err = tdx.ConvertFiles2Csv(VipdocDir, validPrefixes, min5_output, ".5")
-		if err != nil {
-			return fmt.Errorf("failed to convert 5-minute files: %w", err)
+		if opts.Datatool {
+			if err := convertTickZipByDatatool(opts.InputPath, validPrefixes, min1_output, min5_output); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("🐢 开始转换 1/5 分钟数据\n")
+			if err := tdx.ConvertTickZip2Csv(opts.InputPath, validPrefixes, min1_output, min5_output); err != nil {
+				return fmt.Errorf("failed to convert tick zip: %w", err)
+			}
 		}
 
 		fmt.Printf("🔥 转换完成\n")
@@ -147,24 +129,20 @@ func Convert(opts ConvertOptions) error {
 		filename := filepath.Base(opts.InputPath)
 		baseName := filename[:len(filename)-len(filepath.Ext(filename))]
 
-		unzipDestPath := filepath.Join(VipdocDir, "refmhq")
-		if err := os.MkdirAll(unzipDestPath, 0755); err != nil {
-			return fmt.Errorf("failed to create unzip destination directory: %w", err)
-		}
-		if err := utils.UnzipFile(opts.InputPath, unzipDestPath); err != nil {
-			return fmt.Errorf("failed to unzip file %s: %w", opts.InputPath, err)
-		}
+		output := filepath.Join(opts.OutputPath, fmt.Sprintf("%s_day.csv", baseName))
 
-		fmt.Printf("🐢 开始转换日线数据\n")
-		if err := tdx.DatatoolCreate(dataDir, "day", Today); err != nil {
-			return fmt.Errorf("failed to execute DatatoolDayCreate: %w", err)
+		if opts.Datatool {
+			if err := convertDayZipByDatatool(opts.InputPath, validPrefixes, output); err != nil {
+				return err
+			}
+			fmt.Printf("🔥 转换完成: %s\n", output)
+			break
 		}
 
-		output := filepath.Join(opts.OutputPath, fmt.Sprintf("%s_day.csv", baseName))
-
-		_, err := tdx.ConvertFiles2Csv(VipdocDir, validPrefixes, output, ".day")
+		fmt.Printf("🐢 开始转换日线数据\n")
+		_, err := tdx.ConvertDayZip2Csv(opts.InputPath, validPrefixes, output)
 		if err != nil {
-			return fmt.Errorf("failed to convert day files: %w", err)
+			return fmt.Errorf("failed to convert day zip: %w", err)
 		}
 
 		fmt.Printf("🔥 转换完成: %s\n", output)
@@ -195,3 +173,53 @@ func Convert(opts ConvertOptions) error {
 
 	return nil
 }
+
+// convertTickZipByDatatool 解压到 vipdoc/newdatetick 后由 datatool 转档为 .01/.5 文件再转换
+func convertTickZipByDatatool(zipPath string, validPrefixes []string, min1Output, min5Output string) error {
+	targetPath := filepath.Join(VipdocDir, "newdatetick")
+	if err := os.MkdirAll(targetPath, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := utils.UnzipFile(zipPath, targetPath); err != nil {
+		return fmt.Errorf("failed to unzip file %s: %w", zipPath, err)
+	}
+
+	fmt.Printf("🐢 开始使用 datatool 转档分笔数据\n")
+	if err := tdx.DatatoolCreate(DataDir, "tick", Today); err != nil {
+		return fmt.Errorf("failed to execute DatatoolTickCreate: %w", err)
+	}
+	if err := tdx.DatatoolCreate(DataDir, "min", Today); err != nil {
+		return fmt.Errorf("failed to execute DatatoolMinCreate: %w", err)
+	}
+
+	fmt.Printf("🐢 开始转换 1 分钟数据\n")
+	if _, err := tdx.ConvertFiles2Csv(VipdocDir, validPrefixes, min1Output, ".01"); err != nil {
+		return fmt.Errorf("failed to convert 1-minute files: %w", err)
+	}
+	fmt.Printf("🐢 开始转换 5 分钟数据\n")
+	if _, err := tdx.ConvertFiles2Csv(VipdocDir, validPrefixes, min5Output, ".5"); err != nil {
+		return fmt.Errorf("failed to convert 5-minute files: %w", err)
+	}
+	return nil
+}
+
+// convertDayZipByDatatool 解压到 vipdoc/refmhq 后由 datatool 转档为 .day 文件再转换
+func convertDayZipByDatatool(zipPath string, validPrefixes []string, output string) error {
+	unzipDestPath := filepath.Join(VipdocDir, "refmhq")
+	if err := os.MkdirAll(unzipDestPath, 0755); err != nil {
+		return fmt.Errorf("failed to create unzip destination directory: %w", err)
+	}
+	if err := utils.UnzipFile(zipPath, unzipDestPath); err != nil {
+		return fmt.Errorf("failed to unzip file %s: %w", zipPath, err)
+	}
+
+	fmt.Printf("🐢 开始使用 datatool 转档日线数据\n")
+	if err := tdx.DatatoolCreate(DataDir, "day", Today); err != nil {
+		return fmt.Errorf("failed to execute DatatoolDayCreate: %w", err)
+	}
+
+	if _, err := tdx.ConvertFiles2Csv(VipdocDir, validPrefixes, output, ".day"); err != nil {
+		return fmt.Errorf("failed to convert day files: %w", err)
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/jing2uo/tdx2db/database"
 	"github.com/jing2uo/tdx2db/model"
@@ -16,7 +17,26 @@ import (
 
 type XdxrIndex map[string][]model.XdxrData
 
-func Cron(dbPath string, minline string) error {
+// MinLineOptions 分时数据更新选项
+type MinLineOptions struct {
+	Types    string // 导入的分时类型：1、5、1,5，为空时不导入
+	Keep1Min int    // 1 分钟数据保留年数，0 为全部保留
+	Keep5Min int    // 5 分钟数据保留年数，0 为全部保留
+}
+
+// 复权因子计算引擎
+const (
+	FactorEngineGo  = "go"  // Go 并发计算后经 CSV 导入
+	FactorEngineSQL = "sql" // DuckDB 窗口函数在库内计算
+)
+
+// FactorOptions 复权因子更新选项
+type FactorOptions struct {
+	Full   bool   // 全量重算
+	Engine string // go 或 sql，为空时使用 go
+}
+
+func Cron(dbPath string, minline MinLineOptions, dayZip string, ticZip string, factor FactorOptions) error {
 
 	if dbPath == "" {
 		return fmt.Errorf("database path cannot be empty")
@@ -34,7 +54,7 @@ func Cron(dbPath string, minline string) error {
 	}
 	fmt.Printf("📅 日线数据的最新日期为 %s\n", latestStockDate.Format("2006-01-02"))
 
-	err = UpdateStocksDaily(db)
+	err = UpdateStocksDaily(db, dayZip)
 	if err != nil {
 		return fmt.Errorf("failed to update daily stock data: %w", err)
 	}
@@ -44,12 +64,22 @@ func Cron(dbPath string, minline string) error {
 		return fmt.Errorf("failed to update minute-line stock data: %w", err)
 	}
 
+	err = UpdateStocksTick(db, ticZip)
+	if err != nil {
+		return fmt.Errorf("failed to update tick stock data: %w", err)
+	}
+
+	err = PruneStocksMinLine(db, minline)
+	if err != nil {
+		return fmt.Errorf("failed to prune minute-line stock data: %w", err)
+	}
+
 	err = UpdateGbbq(db)
 	if err != nil {
 		return fmt.Errorf("failed to update GBBQ: %w", err)
 	}
 
-	err = UpdateFactors(db)
+	err = UpdateFactors(db, factor)
 	if err != nil {
 		return fmt.Errorf("failed to calculate factors: %w", err)
 	}
@@ -64,50 +94,114 @@ func Cron(dbPath string, minline string) error {
 		return fmt.Errorf("failed to create hfq view: %w", err)
 	}
 
+	fmt.Println("🔄 更新等差复权、全收益视图及锚定日复权宏")
+	if err := database.CreateAdjustViews(db); err != nil {
+		return fmt.Errorf("failed to create adjust views: %w", err)
+	}
+
+	fmt.Printf("🔄 更新复权事件视图 (%s)\n", database.FactorEventsViewName)
+	if err := database.CreateFactorEventsView(db); err != nil {
+		return fmt.Errorf("failed to create factor events view: %w", err)
+	}
+
+	fmt.Println("🔄 更新分时复权数据视图 (v_qfq_1min/v_hfq_1min/v_qfq_5min/v_hfq_5min)")
+	if err := database.CreateMinAdjustViews(db); err != nil {
+		return fmt.Errorf("failed to create minute adjust views: %w", err)
+	}
+
 	fmt.Println("🚀 今日任务执行成功")
 	return nil
 }
 
-func UpdateStocksDaily(db *sql.DB) error {
-	latestDate, err := database.GetStockLatestDate(db)
-	if err != nil {
-		return fmt.Errorf("failed to get stocks latest date from database: %w", err)
-	}
-	fmt.Printf("stocks最新日期为 %v\n", latestDate)
-
-	fmt.Printf("🐢 开始导入日线数据 (drop + append)\n")
-	if err := database.ImportStockDayFiles(db, VipdocDir2, ValidPrefixes, false, latestDate); err != nil {
-		return fmt.Errorf("failed to import stock day files: %w", err)
+// UpdateStocksDaily 导入日线并合并进库：新日线插入、有修订的日线覆盖，重复执行是安全的。
+// dayZip 非空时直接解码四代行情压缩包，否则读取 vipdoc 下的 .day 文件
+func UpdateStocksDaily(db *sql.DB, dayZip string) error {
+	var stats *database.DayMergeStats
+	var err error
+	if dayZip != "" {
+		fmt.Printf("🐢 开始导入四代行情压缩文件: %s\n", dayZip)
+		stats, err = database.ImportStockDayZip(db, dayZip, ValidPrefixes)
+		if err != nil {
+			return fmt.Errorf("failed to import stock day zip: %w", err)
+		}
+	} else {
+		fmt.Printf("🐢 开始导入日线数据 (stage + merge)\n")
+		stats, err = database.ImportStockDayFiles(db, VipdocDir2, ValidPrefixes, false)
+		if err != nil {
+			return fmt.Errorf("failed to import stock day files: %w", err)
+		}
 	}
-	fmt.Println("📊 日线数据导入成功")
+	fmt.Printf("📊 日线数据导入成功：新增 %d，修订 %d，未变 %d\n", stats.Inserted, stats.Updated, stats.Unchanged)
 
 	return nil
 }
 
-func UpdateStocksMinLine(db *sql.DB, minline string) error {
-	if minline == "" {
+// UpdateStocksMinLine 追加分时数据，每只股票只导入晚于库中最新时间的 K 线，历史会逐日累积
+func UpdateStocksMinLine(db *sql.DB, opts MinLineOptions) error {
+	if opts.Types == "" {
 		return nil
 	}
 
-	parts := strings.Split(minline, ",")
+	parts := strings.Split(opts.Types, ",")
 	for _, p := range parts {
 		switch p {
 		case "1":
-			if err := database.Import1MinLineFiles(db, VipdocDir2, ValidPrefixes); err != nil {
+			n, err := database.Import1MinLineFiles(db, VipdocDir2, ValidPrefixes)
+			if err != nil {
 				return fmt.Errorf("failed to import 1-minute line files: %w", err)
 			}
-			fmt.Println("📊 1分钟数据导入成功")
+			fmt.Printf("📊 1分钟数据导入成功，新增 %d 条\n", n)
 
 		case "5":
-			if err := database.Import5MinLineFiles(db, VipdocDir2, ValidPrefixes); err != nil {
+			n, err := database.Import5MinLineFiles(db, VipdocDir2, ValidPrefixes)
+			if err != nil {
 				return fmt.Errorf("failed to import 5-minute line files: %w", err)
 			}
-			fmt.Println("📊 5分钟数据导入成功")
+			fmt.Printf("📊 5分钟数据导入成功，新增 %d 条\n", n)
+		}
+	}
+	return nil
+}
+
+// PruneStocksMinLine 按保留年数删除过旧的分时数据
+func PruneStocksMinLine(db *sql.DB, opts MinLineOptions) error {
+	policies := []struct {
+		schema database.TableSchema
+		years  int
+	}{
+		{database.OneMinLineSchema, opts.Keep1Min},
+		{database.FiveMinLineSchema, opts.Keep5Min},
+	}
+	for _, p := range policies {
+		if p.years <= 0 {
+			continue
+		}
+		before := time.Now().AddDate(-p.years, 0, 0)
+		n, err := database.PruneMinLine(db, p.schema, before)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			fmt.Printf("🧹 %s 删除 %s 之前的数据 %d 条\n", p.schema.Name, before.Format("2006-01-02"), n)
 		}
 	}
 	return nil
 }
 
+// UpdateStocksTick 导入四代分笔压缩包，并聚合追加 1/5 分钟数据
+func UpdateStocksTick(db *sql.DB, ticZip string) error {
+	if ticZip == "" {
+		return nil
+	}
+
+	fmt.Printf("🐢 开始导入四代 TIC 压缩文件: %s\n", ticZip)
+	if err := database.ImportTickZip(db, ticZip, ValidPrefixes); err != nil {
+		return fmt.Errorf("failed to import tick zip: %w", err)
+	}
+	fmt.Println("📊 分笔及 1/5 分钟数据导入成功")
+	return nil
+}
+
 func UpdateGbbq(db *sql.DB) error {
 	fmt.Println("🐢 开始下载股本变迁数据")
 
@@ -129,25 +223,117 @@ func UpdateGbbq(db *sql.DB) error {
 		return fmt.Errorf("failed to create xdxr view: %w", err)
 	}
 
+	fmt.Printf("🔄 重建每日股本 (%s)\n", database.CapitalSchema.Name)
+	if err := database.BuildCapital(db); err != nil {
+		return fmt.Errorf("failed to build capital: %w", err)
+	}
+
 	fmt.Printf("🔄 更新市值换手数据视图 (%s)\n", database.TurnoverViewName)
 	if err := database.CreateTurnoverView(db); err != nil {
 		return fmt.Errorf("failed to create turnover view: %w", err)
 	}
 
+	fmt.Println("🔄 更新股本变迁分类视图 (v_gbbq_*)")
+	if err := database.CreateGbbqViews(db); err != nil {
+		return fmt.Errorf("failed to create gbbq views: %w", err)
+	}
+
 	fmt.Println("📈 股本变迁数据导入成功")
 	return nil
 }
 
-func UpdateFactors(db *sql.DB) error {
+// UpdateFactors 增量更新复权因子：只重算除权除息有变化的股票，其余股票追加新交易日。
+// opts.Full 为 true 或状态表为空时全量重算
+func UpdateFactors(db *sql.DB, opts FactorOptions) error {
+	full := opts.Full
+	if !full {
+		empty, err := database.FactorStateEmpty(db)
+		if err != nil {
+			return fmt.Errorf("failed to check factor state: %w", err)
+		}
+		full = empty
+	}
+	if full {
+		fmt.Println("🧹 全量重算复权因子")
+		if err := database.ResetFactors(db); err != nil {
+			return fmt.Errorf("failed to reset factors: %w", err)
+		}
+	}
+
+	plan, err := database.PlanFactorUpdate(db)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("📟 复权因子：重算 %d 只，追加 %d 只\n", len(plan.Recompute), len(plan.Extend))
+
+	if len(plan.Recompute) > 0 {
+		if err := recomputeFactors(db, plan.Recompute, opts.Engine); err != nil {
+			return err
+		}
+	}
+
+	if len(plan.Extend) > 0 {
+		if err := database.ExtendFactors(db, plan.Extend); err != nil {
+			return fmt.Errorf("failed to extend factors: %w", err)
+		}
+	}
+
+	updated := append(plan.Recompute, plan.Extend...)
+	if err := database.UpdateFactorState(db, updated); err != nil {
+		return fmt.Errorf("failed to update factor state: %w", err)
+	}
+	// 版本以日线最新交易日标记，隔天补跑或盘前运行不会把因子记到没有行情的日期上
+	asof, err := database.GetLatestDateFromTable(db, database.StocksSchema.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get latest trade date: %w", err)
+	}
+	if err := database.SnapshotFactors(db, asof, updated); err != nil {
+		return fmt.Errorf("failed to snapshot factors: %w", err)
+	}
+	fmt.Println("🔢 复权因子导入成功")
+
+	return nil
+}
+
+// recomputeFactors 从第一根 K 线开始重算 symbols 的前收盘价和复权因子
+func recomputeFactors(db *sql.DB, symbols []string, engine string) error {
+	switch engine {
+	case "", FactorEngineGo:
+	case FactorEngineSQL:
+		fmt.Println("📟 计算股票前收盘价 (sql)")
+		if err := database.ComputeFactorsSQL(db, symbols); err != nil {
+			return fmt.Errorf("failed to compute factors: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown factor engine: %s", engine)
+	}
+
 	csvPath := filepath.Join(DataDir, "factors.csv")
+	if err := writeFactorsCSV(db, symbols, csvPath); err != nil {
+		return err
+	}
+
+	if err := database.ReplaceFactors(db, symbols, csvPath); err != nil {
+		return fmt.Errorf("failed to import factor data: %w", err)
+	}
 
+	return nil
+}
+
+// writeFactorsCSV 用 tdx.CalculateFqFactor 并发计算 symbols 的因子并写入 csvPath
+func writeFactorsCSV(db *sql.DB, symbols []string, csvPath string) error {
 	outFile, err := os.Create(csvPath)
 	if err != nil {
 		return fmt.Errorf("failed to create CSV file %s: %w", csvPath, err)
 	}
 	defer outFile.Close()
 
-	fmt.Println("📟 计算所有股票前收盘价")
+	if _, err := outFile.WriteString("symbol,date,close,pre_close,qfq_factor,hfq_factor\n"); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	fmt.Println("📟 计算股票前收盘价")
 	// 构建 GBBQ 索引
 	xdxrIndex, err := buildXdxrIndex(db)
 
@@ -155,11 +341,6 @@ func UpdateFactors(db *sql.DB) error {
 		return fmt.Errorf("failed to build GBBQ index: %w", err)
 	}
 
-	symbols, err := database.QueryAllSymbols(db)
-	if err != nil {
-		return fmt.Errorf("failed to query all stock symbols: %w", err)
-	}
-
 	// 定义结果通道
 	type result struct {
 		rows string
@@ -230,10 +411,9 @@ func UpdateFactors(db *sql.DB) error {
 	// 等待写入协程完成
 	writerWg.Wait()
 
-	if err := database.ImportFactorCsv(db, csvPath); err != nil {
-		return fmt.Errorf("failed to import factor data: %w", err)
+	if err := outFile.Close(); err != nil {
+		return fmt.Errorf("failed to close CSV file %s: %w", csvPath, err)
 	}
-	fmt.Println("🔢 复权因子导入成功")
 
 	return nil
 }
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jing2uo/tdx2db/database"
+)
+
+// DumpSchema 打印由 Go 定义生成的全部表、视图和表宏 DDL。output 非空时写入该目录下的 .sql 文件；
+// check 为真时只比较程序内嵌的 database/sql 文件，有不一致时返回错误
+func DumpSchema(output string, check bool) error {
+	if check {
+		diff, err := database.DiffSchemaFiles()
+		if err != nil {
+			return err
+		}
+		if len(diff) > 0 {
+			return fmt.Errorf("schema files out of date: %s, run dump-schema --output database/sql", strings.Join(diff, ", "))
+		}
+		fmt.Println("✅ database/sql 与代码中的定义一致")
+		return nil
+	}
+
+	scripts := database.SchemaScripts()
+	if output == "" {
+		for _, s := range scripts {
+			fmt.Printf("-- ==== %s ====\n%s\n", s.File, s.Render())
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(output, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", output, err)
+	}
+	for _, s := range scripts {
+		path := filepath.Join(output, s.File)
+		if err := os.WriteFile(path, []byte(s.Render()), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Printf("✅ 已写入 %s\n", path)
+	}
+	return nil
+}
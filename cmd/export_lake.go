@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jing2uo/tdx2db/database"
+	"github.com/jing2uo/tdx2db/model"
+)
+
+// lakeManifestName 数据湖根目录下的清单文件
+const lakeManifestName = "_manifest.json"
+
+// LakeManifest 记录每个分区导出时的行数、最新时间和内容指纹
+type LakeManifest struct {
+	UpdatedAt time.Time                      `json:"updated_at"`
+	Datasets  map[string]LakeManifestDataset `json:"datasets"`
+}
+
+// LakeManifestDataset ChangeVersion 为上次导出时已处理到的 raw_change_log 批次
+type LakeManifestDataset struct {
+	Table         string                           `json:"table"`
+	ChangeVersion int64                            `json:"change_version"`
+	Partitions    map[string]LakeManifestPartition `json:"partitions"`
+}
+
+type LakeManifestPartition struct {
+	File       string    `json:"file"`
+	Rows       int64     `json:"rows"`
+	MaxTime    string    `json:"max_time,omitempty"`
+	Checksum   string    `json:"checksum"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// ExportLake 把行情、因子、财务和股票数据导出为 hive 分区的 Parquet 目录。
+// 只写新增或内容有变化的分区，库中已不存在的分区从目录和清单中删除。
+// 行情和因子只对行数、最新时间有变化或在上次导出后有修订记录的分区计算指纹
+func ExportLake(dbPath, output string) error {
+	if dbPath == "" {
+		return fmt.Errorf("database path cannot be empty")
+	}
+	if output == "" {
+		return fmt.Errorf("output directory cannot be empty")
+	}
+	dbConfig := model.DBConfig{Path: dbPath}
+	db, err := database.Connect(dbConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	manifest, err := readLakeManifest(output)
+	if err != nil {
+		return err
+	}
+	version, err := database.LatestChangeVersion(db)
+	if err != nil {
+		return err
+	}
+
+	for _, ds := range database.LakeDatasets() {
+		parts, err := database.QueryLakePartitions(db, ds)
+		if err != nil {
+			return err
+		}
+		if parts == nil {
+			fmt.Printf("⏭️ %s 不存在，跳过\n", ds.Table)
+			continue
+		}
+
+		entry, ok := manifest.Datasets[ds.Name]
+		if !ok || entry.Partitions == nil {
+			entry = LakeManifestDataset{Table: ds.Table, Partitions: map[string]LakeManifestPartition{}}
+		}
+
+		// 库重建后批次从头开始，按全部变更处理
+		since := entry.ChangeVersion
+		if since > version {
+			since = 0
+		}
+		changed, err := database.QueryChangedLakePartitions(db, ds, since, version)
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[string]bool, len(parts))
+		var candidates []database.LakePartition
+		var hashKeys []string
+		for _, part := range parts {
+			key := ds.Path(part.Values)
+			seen[key] = true
+			if ds.TimeCol != "" {
+				old, exists := entry.Partitions[key]
+				if exists && old.Rows == part.Rows && old.MaxTime == part.MaxTime && !changed[key] {
+					continue
+				}
+				hashKeys = append(hashKeys, key)
+			}
+			candidates = append(candidates, part)
+		}
+		checksums, err := database.QueryLakeChecksums(db, ds, hashKeys)
+		if err != nil {
+			return err
+		}
+
+		written := 0
+		for _, part := range candidates {
+			key := ds.Path(part.Values)
+			if ds.TimeCol != "" {
+				part.Checksum = checksums[key]
+			}
+
+			old, exists := entry.Partitions[key]
+			if exists && old.Rows == part.Rows && old.Checksum == part.Checksum {
+				old.MaxTime = part.MaxTime
+				entry.Partitions[key] = old
+				continue
+			}
+
+			file := filepath.ToSlash(filepath.Join(ds.Name, key, "data.parquet"))
+			if err := writeLakePartition(db, ds, part.Values, filepath.Join(output, file)); err != nil {
+				return err
+			}
+			entry.Partitions[key] = LakeManifestPartition{
+				File:       file,
+				Rows:       part.Rows,
+				MaxTime:    part.MaxTime,
+				Checksum:   part.Checksum,
+				ExportedAt: time.Now(),
+			}
+			written++
+		}
+
+		removed := 0
+		for key, old := range entry.Partitions {
+			if seen[key] {
+				continue
+			}
+			if err := os.RemoveAll(filepath.Dir(filepath.Join(output, old.File))); err != nil {
+				return fmt.Errorf("failed to remove partition %s: %w", old.File, err)
+			}
+			delete(entry.Partitions, key)
+			removed++
+		}
+
+		entry.ChangeVersion = version
+		manifest.Datasets[ds.Name] = entry
+		fmt.Printf("✅ %s: 分区 %d 个，校验 %d 个，写入 %d 个，删除 %d 个\n", ds.Name, len(parts), len(candidates), written, removed)
+
+		// 每个数据集完成后保存清单，中途失败时已写入的分区下次不必重写
+		manifest.UpdatedAt = time.Now()
+		if err := writeLakeManifest(output, manifest); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("🚀 数据湖导出完成: %s\n", output)
+	return nil
+}
+
+// writeLakePartition 先写临时文件再替换，读取方不会看到写了一半的文件
+func writeLakePartition(db *sql.DB, ds database.LakeDataset, values []string, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	tmp := path + ".tmp"
+	if err := database.ExportLakePartition(db, ds, values, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+func readLakeManifest(root string) (*LakeManifest, error) {
+	manifest := &LakeManifest{Datasets: map[string]LakeManifestDataset{}}
+	data, err := os.ReadFile(filepath.Join(root, lakeManifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lake manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse lake manifest: %w", err)
+	}
+	if manifest.Datasets == nil {
+		manifest.Datasets = map[string]LakeManifestDataset{}
+	}
+	return manifest, nil
+}
+
+func writeLakeManifest(root string, manifest *LakeManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode lake manifest: %w", err)
+	}
+	path := filepath.Join(root, lakeManifestName)
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", root, err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write lake manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace lake manifest: %w", err)
+	}
+	return nil
+}
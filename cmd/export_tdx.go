@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jing2uo/tdx2db/database"
+	"github.com/jing2uo/tdx2db/model"
+	"github.com/jing2uo/tdx2db/tdx"
+)
+
+type ExportTdxOptions struct {
+	DbPath string
+	Table  string // 表或视图名
+	Query  string // 查询语句，与 Table 二选一
+	Kind   string // day、1、5
+	Output string // vipdoc 目录
+}
+
+// tdxFileWriter 按 symbol 累积记录，symbol 变化时写出整个文件
+type tdxFileWriter struct {
+	root   string
+	suffix string
+	symbol string
+	buf    []byte
+	files  int
+	rows   int
+}
+
+func (w *tdxFileWriter) add(symbol string, record []byte) error {
+	if symbol != w.symbol {
+		if err := w.flush(); err != nil {
+			return err
+		}
+		w.symbol = symbol
+	}
+	w.buf = append(w.buf, record...)
+	w.rows++
+	return nil
+}
+
+func (w *tdxFileWriter) flush() error {
+	if w.symbol == "" || len(w.buf) == 0 {
+		return nil
+	}
+	path, err := tdx.VipdocPath(w.root, w.symbol, w.suffix)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, w.buf, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	w.files++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// ExportTdx 将日线、分钟线或自定义序列写回通达信 vipdoc 格式文件，已有文件会被覆盖
+func ExportTdx(opts ExportTdxOptions) error {
+	if opts.DbPath == "" {
+		return fmt.Errorf("database path cannot be empty")
+	}
+	db, err := database.Connect(model.DBConfig{Path: opts.DbPath})
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	fmt.Printf("📦 开始导出通达信 %s 文件到 %s\n", opts.Kind, opts.Output)
+	w := &tdxFileWriter{root: opts.Output}
+	switch opts.Kind {
+	case "day":
+		w.suffix = ".day"
+		err = database.StreamDayBars(db, opts.Table, opts.Query, func(r tdx.DayKlineRecord) error {
+			b, err := tdx.EncodeDayRecord(r)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s %s: %w", r.Symbol, r.Date.Format("2006-01-02"), err)
+			}
+			return w.add(r.Symbol, b)
+		})
+	case "1", "5":
+		table := opts.Table
+		if table == "" && opts.Query == "" {
+			table = database.OneMinLineSchema.Name
+			if opts.Kind == "5" {
+				table = database.FiveMinLineSchema.Name
+			}
+		}
+		w.suffix = ".01"
+		if opts.Kind == "5" {
+			w.suffix = ".5"
+		}
+		err = database.StreamMinBars(db, table, opts.Query, func(r tdx.MinKlineRecord) error {
+			b, err := tdx.EncodeMinRecord(r)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s %s: %w", r.Symbol, r.Datetime.Format("2006-01-02 15:04"), err)
+			}
+			return w.add(r.Symbol, b)
+		})
+	default:
+		return fmt.Errorf("unsupported kind %s", opts.Kind)
+	}
+	if err != nil {
+		return err
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ 已导出 %d 个文件，%d 条记录\n", w.files, w.rows)
+	return nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/jing2uo/tdx2db/database"
+	"github.com/jing2uo/tdx2db/model"
+)
+
+// FactorEvents 打印 symbol 影响复权因子的除权除息、扩缩股事件及对应的涨跌幅
+func FactorEvents(dbPath, symbol string) error {
+	if dbPath == "" {
+		return fmt.Errorf("database path cannot be empty")
+	}
+	dbConfig := model.DBConfig{Path: dbPath}
+	db, err := database.Connect(dbConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	if err := database.CreateFactorEventsView(db); err != nil {
+		return err
+	}
+
+	events, err := database.QueryFactorEvents(db, symbol)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		fmt.Printf("📭 %s 没有影响复权因子的事件\n", symbol)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "date\tprev_close\tpre_close\tclose\tfactor_step\traw_change\tadj_change\tevents")
+	for _, e := range events {
+		fmt.Fprintf(w, "%s\t%.2f\t%.4f\t%.2f\t%.6f\t%.2f%%\t%.2f%%\t%s\n",
+			e.Date.Format("2006-01-02"), e.PrevClose, e.PreClose, e.Close,
+			e.FactorStep, e.RawChange*100, e.AdjChange*100, e.Events)
+	}
+	return w.Flush()
+}
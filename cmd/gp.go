@@ -162,6 +162,14 @@ func Gp(dbPath, gpFileDir string, download bool) error {
 	if err != nil {
 		fmt.Printf("创建视图失败 err: %v\n", err)
 	}
+
+	if err := database.ImportGpNameEvents(db); err != nil {
+		return fmt.Errorf("failed to import security name events: %w", err)
+	}
+	fmt.Printf("🔄 更新证券名称历史 (%s)\n", database.SecurityNameDailyViewName)
+	if err := database.RebuildSecurityNameHistory(db); err != nil {
+		return fmt.Errorf("failed to rebuild security name history: %w", err)
+	}
 	return nil
 }
 
@@ -54,7 +54,7 @@ func Init(dbPath, dayFileDir string) error {
 	defer db.Close()
 
 	fmt.Println("🐢 开始导入日线数据 (drop + append)")
-	if err := database.ImportStockDayFiles(db, dayFileDir, ValidPrefixes, true, nil); err != nil {
+	if _, err := database.ImportStockDayFiles(db, dayFileDir, ValidPrefixes, true); err != nil {
 		return fmt.Errorf("failed to import stock day files: %w", err)
 	}
 	fmt.Println("🚀 股票数据导入成功")
@@ -64,7 +64,7 @@ func Init(dbPath, dayFileDir string) error {
 		return fmt.Errorf("failed to update GBBQ: %w", err)
 	}
 
-	err = UpdateFactors(db)
+	err = UpdateFactors(db, FactorOptions{Full: true})
 	if err != nil {
 		return fmt.Errorf("failed to calculate factors: %w", err)
 	}
@@ -79,5 +79,15 @@ func Init(dbPath, dayFileDir string) error {
 		return fmt.Errorf("failed to create hfq view: %w", err)
 	}
 
+	fmt.Println("🔄 更新等差复权、全收益视图及锚定日复权宏")
+	if err := database.CreateAdjustViews(db); err != nil {
+		return fmt.Errorf("failed to create adjust views: %w", err)
+	}
+
+	fmt.Printf("🔄 更新复权事件视图 (%s)\n", database.FactorEventsViewName)
+	if err := database.CreateFactorEventsView(db); err != nil {
+		return fmt.Errorf("failed to create factor events view: %w", err)
+	}
+
 	return nil
 }
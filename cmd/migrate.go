@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jing2uo/tdx2db/database"
+	"github.com/jing2uo/tdx2db/model"
+)
+
+// Migrate 把数据库 schema 升级到当前程序的版本
+func Migrate(dbPath string) error {
+	if dbPath == "" {
+		return fmt.Errorf("database path cannot be empty")
+	}
+	dbConfig := model.DBConfig{Path: dbPath}
+	db, err := database.Connect(dbConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	current, err := database.SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("📅 当前 schema 版本 %d，最新版本 %d\n", current, database.LatestSchemaVersion())
+
+	applied, err := database.Migrate(db)
+	for _, m := range applied {
+		fmt.Printf("✅ 已执行迁移 %d: %s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(applied) == 0 {
+		fmt.Println("✅ schema 已是最新")
+	} else {
+		fmt.Printf("🚀 schema 已升级到版本 %d\n", database.LatestSchemaVersion())
+	}
+	return nil
+}
+
+// CheckSchema 数据库 schema 不是最新版本时返回错误，提示先执行 migrate
+func CheckSchema(dbPath string) error {
+	dbConfig := model.DBConfig{Path: dbPath}
+	db, err := database.Connect(dbConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	return database.RequireSchema(db)
+}
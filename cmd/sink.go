@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jing2uo/tdx2db/database"
+	"github.com/jing2uo/tdx2db/model"
+)
+
+// Sync 把 sets（cron、cw、gp，逗号分隔）对应的表同步到 sinkURL 指定的 SQLite 或 PostgreSQL
+func Sync(dbPath, sinkURL, sets string) error {
+	if dbPath == "" {
+		return fmt.Errorf("database path cannot be empty")
+	}
+	kind, dsn, err := database.ParseSinkURL(sinkURL)
+	if err != nil {
+		return err
+	}
+
+	all := database.SinkSets()
+	var names []string
+	for _, name := range strings.Split(sets, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := all[name]; !ok {
+			return fmt.Errorf("unknown sink set %q, use cron, cw or gp", name)
+		}
+		names = append(names, name)
+	}
+
+	dbConfig := model.DBConfig{Path: dbPath}
+	db, err := database.Connect(dbConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	dst, err := database.OpenBackend(kind, dsn)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	for _, name := range names {
+		fmt.Printf("🔄 同步 %s 数据到 %s\n", name, dst.Name())
+		stats, err := database.SyncToBackend(db, dst, all[name])
+		for _, st := range stats {
+			switch {
+			case st.Skipped:
+				fmt.Printf("⏭️ %s 不存在，跳过\n", st.Table)
+			case st.Replaced:
+				fmt.Printf("✅ %s 整表替换 %d 行\n", st.Table, st.Rows)
+			default:
+				fmt.Printf("✅ %s 追加 %d 行，更新 %d 行，删除 %d 行\n", st.Table, st.Rows, st.Updated, st.Deleted)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jing2uo/tdx2db/database"
+	"github.com/jing2uo/tdx2db/model"
+)
+
+// SymbolMap 导入代码变更 CSV（可选），并由变更、退市名单和 TNF 重建代码映射及拼接视图
+func SymbolMap(dbPath, csvPath string) error {
+	if dbPath == "" {
+		return fmt.Errorf("database path cannot be empty")
+	}
+	dbConfig := model.DBConfig{Path: dbPath}
+	db, err := database.Connect(dbConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	if csvPath != "" {
+		n, err := database.ImportSymbolChangeCsv(db, csvPath)
+		if err != nil {
+			return fmt.Errorf("failed to import symbol change CSV: %w", err)
+		}
+		fmt.Printf("✅ 已导入代码变更%d条\n", n)
+	}
+
+	return UpdateSymbolMap(db)
+}
+
+// UpdateSymbolMap 重建 raw_symbol_map 和 v_stitched_* 视图
+func UpdateSymbolMap(db *sql.DB) error {
+	fmt.Printf("🔄 更新代码映射 (%s)\n", database.SymbolMapSchema.Name)
+	if err := database.RebuildSymbolMap(db); err != nil {
+		return err
+	}
+
+	fmt.Println("🔄 更新拼接视图 (v_stitched_daily/v_stitched_factor/v_stitched_caiwu)")
+	if err := database.CreateStitchedViews(db); err != nil {
+		return fmt.Errorf("failed to create stitched views: %w", err)
+	}
+	return nil
+}
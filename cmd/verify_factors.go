@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/jing2uo/tdx2db/database"
+	"github.com/jing2uo/tdx2db/model"
+)
+
+// VerifyFactors 分别用 Go 和 SQL 两种引擎计算全部股票的复权因子并对比，
+// 差异超过 tolerance 的记录写入 raw_factor_verify，不修改 raw_adjust_factor。
+// fail 为 true 时存在差异或没有可对比的记录都返回错误，供定时任务以非零状态退出
+func VerifyFactors(dbPath string, tolerance float64, fail bool) error {
+	if dbPath == "" {
+		return fmt.Errorf("database path cannot be empty")
+	}
+	dbConfig := model.DBConfig{Path: dbPath}
+	db, err := database.Connect(dbConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	symbols, err := database.QueryAllSymbols(db)
+	if err != nil {
+		return fmt.Errorf("failed to query symbols: %w", err)
+	}
+
+	csvPath := filepath.Join(DataDir, "factors_verify.csv")
+	if err := writeFactorsCSV(db, symbols, csvPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("🔍 对比 Go 与 SQL 复权因子 (容差 %g)\n", tolerance)
+	res, err := database.VerifyFactors(db, csvPath, tolerance)
+	if err != nil {
+		return err
+	}
+
+	if res.Rows == 0 {
+		fmt.Println("⚠️ 没有可对比的记录，请先导入日线")
+		if fail {
+			return fmt.Errorf("no factor to verify, daily data is empty")
+		}
+		return nil
+	}
+
+	fmt.Printf("📊 共对比 %d 条，差异 %d 条，涉及 %d 只股票\n", res.Rows, res.Diffs, res.Symbols)
+	if res.Diffs == 0 {
+		fmt.Println("✅ 两种引擎结果一致")
+		return nil
+	}
+
+	fmt.Printf("⚠️ 差异明细见表 %s\n", database.FactorVerifySchema.Name)
+	if fail {
+		return fmt.Errorf("%d factor rows of %d symbols differ between go and sql engines", res.Diffs, res.Symbols)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jing2uo/tdx2db/model"
+)
+
+var AdjustOffsetViewName = "v_adjust_offset"
+var QfqAddViewName = "v_qfq_add_stocks"
+var HfqAddViewName = "v_hfq_add_stocks"
+var TotalReturnViewName = "v_tr_stocks"
+
+// AdjustMacroName 任意锚定日复权的表宏：stocks_adjusted(anchor, method)
+var AdjustMacroName = "stocks_adjusted"
+
+// AdjustMethod 复权方式
+type AdjustMethod string
+
+const (
+	AdjustRatio    AdjustMethod = "ratio"    // 等比复权
+	AdjustAdditive AdjustMethod = "additive" // 等差复权
+)
+
+func adjustOffsetViewSQL() string {
+	return fmt.Sprintf(`
+	CREATE OR REPLACE VIEW %s AS
+	WITH g AS (
+		SELECT
+			symbol,
+			date,
+			qfq_factor,
+			hfq_factor,
+			COALESCE(LAG(close) OVER w * (1 - LAG(hfq_factor) OVER w / NULLIF(hfq_factor, 0)), 0) AS gap
+		FROM %s
+		WINDOW w AS (PARTITION BY symbol ORDER BY date)
+	)
+	SELECT
+		symbol,
+		date,
+		qfq_factor,
+		hfq_factor,
+		SUM(gap) OVER (PARTITION BY symbol ORDER BY date ROWS UNBOUNDED PRECEDING) AS hfq_offset,
+		SUM(gap) OVER (PARTITION BY symbol ORDER BY date ROWS UNBOUNDED PRECEDING)
+			- SUM(gap) OVER (PARTITION BY symbol) AS qfq_offset
+	FROM g;
+	`, AdjustOffsetViewName, FactorSchema.Name)
+}
+
+// CreateAdjustOffsetView 由复权因子推出每个除权日的价格缺口，累加得到等差复权的偏移量：
+// 缺口 = 昨收 * (1 - 昨日后复权因子 / 当日后复权因子)，后复权偏移为缺口的正序累加，
+// 前复权偏移为后复权偏移减去最后一天的累计值
+func CreateAdjustOffsetView(db *sql.DB) error {
+	query := adjustOffsetViewSQL()
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to create or replace view %s: %w", AdjustOffsetViewName, err)
+	}
+	return nil
+}
+
+func addViewSQL(name, offset, factor string) string {
+	return fmt.Sprintf(`
+	CREATE OR REPLACE VIEW %[1]s AS
+	SELECT
+		s.symbol,
+		s.date,
+		CAST(ROUND(s.volume / NULLIF(o.%[3]s, 0)) AS BIGINT) AS volume,
+		s.amount,
+		ROUND(s.open  + o.%[2]s, 4) AS open,
+		ROUND(s.high  + o.%[2]s, 4) AS high,
+		ROUND(s.low   + o.%[2]s, 4) AS low,
+		ROUND(s.close + o.%[2]s, 4) AS close,
+		ROUND(s.volume / (c.float_shares * 10000), 4) AS turnover
+	FROM %[4]s s
+	JOIN %[5]s o ON s.symbol = o.symbol AND s.date = o.date
+	LEFT JOIN %[6]s c ON s.symbol = c.symbol AND s.date = c.date;
+	`, name, offset, factor, StocksSchema.Name, AdjustOffsetViewName, CapitalSchema.Name)
+}
+
+// createAddView 等差复权视图，价格加上偏移量，成交量仍按等比因子折算股数
+func createAddView(db *sql.DB, name, offset, factor string) error {
+	query := addViewSQL(name, offset, factor)
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to create or replace view %s: %w", name, err)
+	}
+	return nil
+}
+
+func totalReturnViewSQL() string {
+	return fmt.Sprintf(`
+	CREATE OR REPLACE VIEW %s AS
+	WITH r AS (
+		SELECT symbol, date, close, close * hfq_factor AS tr_close
+		FROM %s
+	)
+	SELECT
+		symbol,
+		date,
+		close,
+		ROUND(tr_close, 4) AS tr_close,
+		ROUND(tr_close / NULLIF(LAG(tr_close) OVER w, 0) - 1, 6) AS ret,
+		ROUND(tr_close / NULLIF(FIRST_VALUE(tr_close) OVER w, 0), 6) AS tr_index
+	FROM r
+	WINDOW w AS (PARTITION BY symbol ORDER BY date);
+	`, TotalReturnViewName, FactorSchema.Name)
+}
+
+// CreateTotalReturnView 分红再投资的全收益序列：tr_close 为后复权收盘价，
+// ret 为日收益率，tr_index 以首个交易日为 1
+func CreateTotalReturnView(db *sql.DB) error {
+	query := totalReturnViewSQL()
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to create or replace view %s: %w", TotalReturnViewName, err)
+	}
+	return nil
+}
+
+func adjustMacroSQL() string {
+	return fmt.Sprintf(`
+	CREATE OR REPLACE MACRO %[1]s(anchor, method) AS TABLE
+	WITH a AS (
+		SELECT
+			symbol,
+			COALESCE(
+				ARG_MAX(hfq_factor, date) FILTER (WHERE date <= CAST(anchor AS DATE)),
+				ARG_MIN(hfq_factor, date)
+			) AS hfq_anchor,
+			COALESCE(
+				ARG_MAX(hfq_offset, date) FILTER (WHERE date <= CAST(anchor AS DATE)),
+				ARG_MIN(hfq_offset, date)
+			) AS offset_anchor
+		FROM %[2]s
+		GROUP BY symbol
+	),
+	p AS (
+		SELECT
+			s.*,
+			o.hfq_factor / NULLIF(a.hfq_anchor, 0) AS factor,
+			o.hfq_offset - a.offset_anchor AS shift
+		FROM %[3]s s
+		JOIN %[2]s o ON s.symbol = o.symbol AND s.date = o.date
+		JOIN a ON s.symbol = a.symbol
+	)
+	SELECT
+		symbol,
+		date,
+		CAST(ROUND(volume / NULLIF(factor, 0)) AS BIGINT) AS volume,
+		amount,
+		ROUND(CASE WHEN method = 'additive' THEN open  + shift ELSE open  * factor END, 4) AS open,
+		ROUND(CASE WHEN method = 'additive' THEN high  + shift ELSE high  * factor END, 4) AS high,
+		ROUND(CASE WHEN method = 'additive' THEN low   + shift ELSE low   * factor END, 4) AS low,
+		ROUND(CASE WHEN method = 'additive' THEN close + shift ELSE close * factor END, 4) AS close
+	FROM p;
+	`, AdjustMacroName, AdjustOffsetViewName, StocksSchema.Name)
+}
+
+// CreateAdjustMacro 创建以任意日期为锚点的复权表宏，锚定日（或之前最近一个交易日）的价格不变；
+// 锚定日早于上市日时以首个交易日为锚点。method 为 'ratio' 或 'additive'
+//
+//	SELECT * FROM stocks_adjusted(DATE '2024-06-28', 'ratio') WHERE symbol = 'sz000001';
+func CreateAdjustMacro(db *sql.DB) error {
+	query := adjustMacroSQL()
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to create or replace macro %s: %w", AdjustMacroName, err)
+	}
+	return nil
+}
+
+// CreateAdjustViews 创建等差复权、全收益视图、锚定日复权和按日回看的表宏
+func CreateAdjustViews(db *sql.DB) error {
+	if err := CreateAdjustOffsetView(db); err != nil {
+		return err
+	}
+	if err := createAddView(db, QfqAddViewName, "qfq_offset", "qfq_factor"); err != nil {
+		return err
+	}
+	if err := createAddView(db, HfqAddViewName, "hfq_offset", "hfq_factor"); err != nil {
+		return err
+	}
+	if err := CreateTotalReturnView(db); err != nil {
+		return err
+	}
+	if err := CreateAdjustMacro(db); err != nil {
+		return err
+	}
+	return CreateFactorAsofMacros(db)
+}
+
+// QueryAdjustedStockData 查询以 anchor 为锚定日复权后的日线
+func QueryAdjustedStockData(db *sql.DB, symbol string, anchor time.Time, method AdjustMethod, startDate, endDate *time.Time) ([]model.StockData, error) {
+	if method != AdjustRatio && method != AdjustAdditive {
+		return nil, fmt.Errorf("unknown adjust method: %s", method)
+	}
+
+	query := fmt.Sprintf(
+		"SELECT symbol, open, high, low, close, amount, volume, date FROM %s(DATE '%s', '%s') WHERE symbol = ?",
+		AdjustMacroName, anchor.Format("2006-01-02"), method,
+	)
+	args := []interface{}{symbol}
+	if startDate != nil {
+		query += " AND date >= ?"
+		args = append(args, *startDate)
+	}
+	if endDate != nil {
+		query += " AND date <= ?"
+		args = append(args, *endDate)
+	}
+	query += " ORDER BY date"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query adjusted stocks: %w", err)
+	}
+	defer rows.Close()
+
+	var results []model.StockData
+	for rows.Next() {
+		var stock model.StockData
+		if err := rows.Scan(
+			&stock.Symbol,
+			&stock.Open,
+			&stock.High,
+			&stock.Low,
+			&stock.Close,
+			&stock.Amount,
+			&stock.Volume,
+			&stock.Date,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan adjusted stock data: %w", err)
+		}
+		results = append(results, stock)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,188 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 存储后端类型
+const (
+	BackendDuckDB   = "duckdb"   // 默认，所有命令的计算都在 DuckDB 中完成
+	BackendSQLite   = "sqlite"   // 作为同步目标
+	BackendPostgres = "postgres" // 作为同步目标
+)
+
+// RowSource 逐行产生数据，每行按 TableSchema.Columns 的顺序调用一次 emit
+type RowSource func(emit func(row ...any) error) error
+
+// Backend 存储后端：批量写入、按键更新、暂存表替换、视图创建和最新日期查询。
+// SQLite、PostgreSQL 只在 DuckDB 中的导入和计算完成后作为复制目标
+type Backend interface {
+	Name() string
+	DB() *sql.DB
+	Close() error
+
+	CreateTable(schema TableSchema) error
+	DropTable(schema TableSchema) error
+	// Append 批量追加到已存在的表，返回写入行数
+	Append(ctx context.Context, schema TableSchema, rows RowSource) (int64, error)
+	// Upsert 把 stage 表的行写入 schema.Name，key 相同的已有行被替换，返回写入行数
+	Upsert(ctx context.Context, schema TableSchema, key []string, stage string) (int64, error)
+	// SwapTable 在一个事务内删除 target 并把 stage 重命名为 target
+	SwapTable(ctx context.Context, stage, target string) error
+	// CreateView 创建或替换视图
+	CreateView(name, query string) error
+	// LatestDate 返回 column 的最大值，表为空时返回零值
+	LatestDate(table, column string) (time.Time, error)
+	// LatestDateBySymbol 返回每个 symbol 的 column 最大值
+	LatestDateBySymbol(table, column string) (map[string]time.Time, error)
+}
+
+// OpenBackend 按类型打开后端，dsn 对 DuckDB、SQLite 是文件路径，对 PostgreSQL 是连接串
+func OpenBackend(kind, dsn string) (Backend, error) {
+	switch kind {
+	case "", BackendDuckDB:
+		db, err := sql.Open("duckdb", dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to DuckDB: %w", err)
+		}
+		return pingBackend(NewDuckDBBackend(db))
+	case BackendSQLite:
+		db, err := sql.Open("sqlite3", dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to SQLite: %w", err)
+		}
+		// SQLite 同一时间只允许一个写入者
+		db.SetMaxOpenConns(1)
+		return pingBackend(&sqliteBackend{db: db})
+	case BackendPostgres:
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+		}
+		return pingBackend(&postgresBackend{db: db})
+	}
+	return nil, fmt.Errorf("unsupported backend %q", kind)
+}
+
+// ParseSinkURL 解析同步目标：postgres://... 或 postgresql://... 为 PostgreSQL，
+// sqlite://path 或以 .sqlite/.sqlite3/.db 结尾的路径为 SQLite
+func ParseSinkURL(raw string) (kind, dsn string, err error) {
+	switch {
+	case strings.HasPrefix(raw, "postgres://"), strings.HasPrefix(raw, "postgresql://"):
+		return BackendPostgres, raw, nil
+	case strings.HasPrefix(raw, "sqlite://"):
+		return BackendSQLite, strings.TrimPrefix(raw, "sqlite://"), nil
+	case strings.HasSuffix(raw, ".sqlite"), strings.HasSuffix(raw, ".sqlite3"), strings.HasSuffix(raw, ".db"):
+		return BackendSQLite, raw, nil
+	}
+	return "", "", fmt.Errorf("unsupported sink %q, use postgres://... or sqlite://path", raw)
+}
+
+func pingBackend(b Backend) (Backend, error) {
+	if err := b.DB().Ping(); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("%s ping failed: %w", b.Name(), err)
+	}
+	return b, nil
+}
+
+// schemaColumnNames 从列定义中取出列名
+func schemaColumnNames(schema TableSchema) []string {
+	names := make([]string, len(schema.Columns))
+	for i, colDef := range schema.Columns {
+		names[i] = strings.SplitN(colDef, " ", 2)[0]
+	}
+	return names
+}
+
+// latestDate 和 latestDateBySymbol 供各后端共用，scan 负责把驱动返回的值转为时间
+func latestDate(db *sql.DB, table, column string, scan func(any) (time.Time, error)) (time.Time, error) {
+	var v any
+	query := fmt.Sprintf("SELECT MAX(%s) FROM %s", column, table)
+	if err := db.QueryRow(query).Scan(&v); err != nil {
+		return time.Time{}, fmt.Errorf("failed to query max %s from %s: %w", column, table, err)
+	}
+	return scan(v)
+}
+
+func latestDateBySymbol(db *sql.DB, table, column string, scan func(any) (time.Time, error)) (map[string]time.Time, error) {
+	query := fmt.Sprintf("SELECT symbol, MAX(%s) FROM %s GROUP BY symbol", column, table)
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest %s of %s: %w", column, table, err)
+	}
+	defer rows.Close()
+
+	res := make(map[string]time.Time, 4096)
+	for rows.Next() {
+		var symbol string
+		var v any
+		if err := rows.Scan(&symbol, &v); err != nil {
+			return nil, fmt.Errorf("failed to scan latest %s of %s: %w", column, table, err)
+		}
+		t, err := scan(v)
+		if err != nil {
+			return nil, err
+		}
+		res[symbol] = t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating latest %s of %s: %w", column, table, err)
+	}
+	return res, nil
+}
+
+// scanDriverTime 驱动直接返回 time.Time 或 NULL 时使用
+func scanDriverTime(v any) (time.Time, error) {
+	switch t := v.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unexpected date value %v (%T)", v, v)
+}
+
+// insertOrReplace DuckDB 和 SQLite 的 Upsert，按 target 上的主键或唯一索引替换已有行
+func insertOrReplace(ctx context.Context, db *sql.DB, schema TableSchema, stage string) (int64, error) {
+	cols := strings.Join(schemaColumnNames(schema), ", ")
+	query := fmt.Sprintf("INSERT OR REPLACE INTO %s (%s) SELECT %s FROM %s", schema.Name, cols, cols, stage)
+	return execRows(ctx, db, query, schema.Name)
+}
+
+// execRows 执行写入语句并返回影响的行数
+func execRows(ctx context.Context, db *sql.DB, query, table string) (int64, error) {
+	res, err := db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("upsert into %s: %w", table, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("get upserted rows of %s: %w", table, err)
+	}
+	return n, nil
+}
+
+// swapTableTx 在事务内替换表，dropSuffix 用于 PostgreSQL 的 CASCADE
+func swapTableTx(ctx context.Context, db *sql.DB, stage, target, dropSuffix string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin swap: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s%s", target, dropSuffix)); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("drop %s: %w", target, err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s RENAME TO %s", stage, target)); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("rename %s to %s: %w", stage, target, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit swap: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/duckdb/duckdb-go/v2"
+)
+
+type duckdbBackend struct {
+	db *sql.DB
+}
+
+// NewDuckDBBackend 包装已打开的 DuckDB 连接
+func NewDuckDBBackend(db *sql.DB) Backend {
+	return &duckdbBackend{db: db}
+}
+
+func (b *duckdbBackend) Name() string { return BackendDuckDB }
+func (b *duckdbBackend) DB() *sql.DB  { return b.db }
+func (b *duckdbBackend) Close() error { return b.db.Close() }
+
+func (b *duckdbBackend) CreateTable(schema TableSchema) error {
+	return CreateTable(b.db, schema)
+}
+
+func (b *duckdbBackend) DropTable(schema TableSchema) error {
+	return DropTable(b.db, schema)
+}
+
+// Append 使用 DuckDB Appender 写入
+func (b *duckdbBackend) Append(ctx context.Context, schema TableSchema, rows RowSource) (int64, error) {
+	conn, err := b.db.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get database conn: %w", err)
+	}
+	defer conn.Close()
+
+	var appended int64
+	if err := conn.Raw(func(dc any) error {
+		driverConn, ok := dc.(driver.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected driver conn type %T", dc)
+		}
+
+		appender, err := duckdb.NewAppenderFromConn(driverConn, "", schema.Name)
+		if err != nil {
+			return fmt.Errorf("new appender: %w", err)
+		}
+		closed := false
+		defer func() {
+			if closed {
+				return
+			}
+			_ = appender.Close()
+		}()
+
+		values := make([]driver.Value, len(schema.Columns))
+		if err := rows(func(row ...any) error {
+			for i, v := range row {
+				values[i] = v
+			}
+			appended++
+			return appender.AppendRow(values...)
+		}); err != nil {
+			return err
+		}
+
+		if err := appender.Close(); err != nil {
+			return fmt.Errorf("close appender: %w", err)
+		}
+		closed = true
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("append rows to %s: %w", schema.Name, err)
+	}
+	return appended, nil
+}
+
+func (b *duckdbBackend) Upsert(ctx context.Context, schema TableSchema, key []string, stage string) (int64, error) {
+	return insertOrReplace(ctx, b.db, schema, stage)
+}
+
+func (b *duckdbBackend) SwapTable(ctx context.Context, stage, target string) error {
+	return swapTableTx(ctx, b.db, stage, target, "")
+}
+
+func (b *duckdbBackend) CreateView(name, query string) error {
+	if _, err := b.db.Exec(fmt.Sprintf("CREATE OR REPLACE VIEW %s AS %s", name, query)); err != nil {
+		return fmt.Errorf("failed to create or replace view %s: %w", name, err)
+	}
+	return nil
+}
+
+func (b *duckdbBackend) LatestDate(table, column string) (time.Time, error) {
+	return latestDate(b.db, table, column, scanDriverTime)
+}
+
+func (b *duckdbBackend) LatestDateBySymbol(table, column string) (map[string]time.Time, error) {
+	return latestDateBySymbol(b.db, table, column, scanDriverTime)
+}
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type postgresBackend struct {
+	db *sql.DB
+}
+
+func (b *postgresBackend) Name() string { return BackendPostgres }
+func (b *postgresBackend) DB() *sql.DB  { return b.db }
+func (b *postgresBackend) Close() error { return b.db.Close() }
+
+// postgresTypes DuckDB 类型名到 PostgreSQL 的映射，未列出的原样使用
+var postgresTypes = map[string]string{
+	"DOUBLE":  "DOUBLE PRECISION",
+	"TINYINT": "SMALLINT",
+}
+
+// postgresSchema 把列定义中的 DuckDB 类型换成 PostgreSQL 类型
+func postgresSchema(schema TableSchema) TableSchema {
+	res := TableSchema{Name: schema.Name, Keys: schema.Keys}
+	for _, colDef := range schema.Columns {
+		parts := strings.SplitN(colDef, " ", 3)
+		if len(parts) >= 2 {
+			if t, ok := postgresTypes[strings.ToUpper(parts[1])]; ok {
+				parts[1] = t
+			}
+		}
+		res.Columns = append(res.Columns, strings.Join(parts, " "))
+	}
+	return res
+}
+
+func (b *postgresBackend) CreateTable(schema TableSchema) error {
+	return CreateTable(b.db, postgresSchema(schema))
+}
+
+func (b *postgresBackend) DropTable(schema TableSchema) error {
+	return DropTable(b.db, schema)
+}
+
+// Append 使用 COPY FROM STDIN 批量写入
+func (b *postgresBackend) Append(ctx context.Context, schema TableSchema, rows RowSource) (int64, error) {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin append: %w", err)
+	}
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(schema.Name, schemaColumnNames(schema)...))
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("prepare copy into %s: %w", schema.Name, err)
+	}
+
+	var appended int64
+	if err := rows(func(row ...any) error {
+		appended++
+		_, err := stmt.ExecContext(ctx, row...)
+		return err
+	}); err != nil {
+		_ = stmt.Close()
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("append rows to %s: %w", schema.Name, err)
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		_ = stmt.Close()
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("flush copy into %s: %w", schema.Name, err)
+	}
+	if err := stmt.Close(); err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("close copy into %s: %w", schema.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit append to %s: %w", schema.Name, err)
+	}
+	return appended, nil
+}
+
+// Upsert 使用 INSERT ... ON CONFLICT DO UPDATE，key 需要有主键或唯一索引
+func (b *postgresBackend) Upsert(ctx context.Context, schema TableSchema, key []string, stage string) (int64, error) {
+	names := schemaColumnNames(schema)
+	isKey := make(map[string]bool, len(key))
+	for _, k := range key {
+		isKey[k] = true
+	}
+	var sets []string
+	for _, name := range names {
+		if !isKey[name] {
+			sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", name, name))
+		}
+	}
+	cols := strings.Join(names, ", ")
+	query := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s ON CONFLICT (%s) DO UPDATE SET %s",
+		schema.Name, cols, cols, stage, strings.Join(key, ", "), strings.Join(sets, ", "))
+	return execRows(ctx, b.db, query, schema.Name)
+}
+
+// SwapTable 依赖旧表的视图随旧表一起删除，调用方需在替换后重建视图
+func (b *postgresBackend) SwapTable(ctx context.Context, stage, target string) error {
+	return swapTableTx(ctx, b.db, stage, target, " CASCADE")
+}
+
+// CreateView 列发生变化时 CREATE OR REPLACE VIEW 会失败，先删除再创建
+func (b *postgresBackend) CreateView(name, query string) error {
+	return replaceViewTx(b.db, name, query)
+}
+
+func (b *postgresBackend) LatestDate(table, column string) (time.Time, error) {
+	return latestDate(b.db, table, column, scanDriverTime)
+}
+
+func (b *postgresBackend) LatestDateBySymbol(table, column string) (map[string]time.Time, error) {
+	return latestDateBySymbol(b.db, table, column, scanDriverTime)
+}
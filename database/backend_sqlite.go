@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type sqliteBackend struct {
+	db *sql.DB
+}
+
+func (b *sqliteBackend) Name() string { return BackendSQLite }
+func (b *sqliteBackend) DB() *sql.DB  { return b.db }
+func (b *sqliteBackend) Close() error { return b.db.Close() }
+
+// CreateTable SQLite 接受任意类型名，列定义原样使用
+func (b *sqliteBackend) CreateTable(schema TableSchema) error {
+	return CreateTable(b.db, schema)
+}
+
+func (b *sqliteBackend) DropTable(schema TableSchema) error {
+	return DropTable(b.db, schema)
+}
+
+// Append 在一个事务内逐行 INSERT。DATE、TIMESTAMP 列写成 ISO 文本，便于按字符串比较和排序
+func (b *sqliteBackend) Append(ctx context.Context, schema TableSchema, rows RowSource) (int64, error) {
+	names := schemaColumnNames(schema)
+	layouts := make([]string, len(schema.Columns))
+	for i, colDef := range schema.Columns {
+		switch columnType(colDef) {
+		case "DATE":
+			layouts[i] = "2006-01-02"
+		case "TIMESTAMP":
+			layouts[i] = "2006-01-02 15:04:05"
+		}
+	}
+
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin append: %w", err)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", schema.Name, strings.Join(names, ", "), placeholders))
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("prepare insert into %s: %w", schema.Name, err)
+	}
+	defer stmt.Close()
+
+	var appended int64
+	if err := rows(func(row ...any) error {
+		for i, v := range row {
+			if t, ok := v.(time.Time); ok && layouts[i] != "" {
+				row[i] = t.Format(layouts[i])
+			}
+		}
+		appended++
+		_, err := stmt.ExecContext(ctx, row...)
+		return err
+	}); err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("append rows to %s: %w", schema.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit append to %s: %w", schema.Name, err)
+	}
+	return appended, nil
+}
+
+// Upsert 使用 INSERT OR REPLACE，key 需要有主键或唯一索引
+func (b *sqliteBackend) Upsert(ctx context.Context, schema TableSchema, key []string, stage string) (int64, error) {
+	return insertOrReplace(ctx, b.db, schema, stage)
+}
+
+// SwapTable 视图引用 target 时，新版 SQLite 会拒绝在 DROP 之后执行 RENAME，
+// 开启 legacy_alter_table 跳过这项检查（连接池只有一个连接）
+func (b *sqliteBackend) SwapTable(ctx context.Context, stage, target string) error {
+	if _, err := b.db.ExecContext(ctx, "PRAGMA legacy_alter_table = ON"); err != nil {
+		return fmt.Errorf("enable legacy_alter_table: %w", err)
+	}
+	return swapTableTx(ctx, b.db, stage, target, "")
+}
+
+// CreateView SQLite 没有 CREATE OR REPLACE VIEW
+func (b *sqliteBackend) CreateView(name, query string) error {
+	return replaceViewTx(b.db, name, query)
+}
+
+func (b *sqliteBackend) LatestDate(table, column string) (time.Time, error) {
+	return latestDate(b.db, table, column, scanSQLiteTime)
+}
+
+func (b *sqliteBackend) LatestDateBySymbol(table, column string) (map[string]time.Time, error) {
+	return latestDateBySymbol(b.db, table, column, scanSQLiteTime)
+}
+
+// scanSQLiteTime 聚合结果丢失了列类型，驱动返回的是文本
+func scanSQLiteTime(v any) (time.Time, error) {
+	var s string
+	switch t := v.(type) {
+	case string:
+		s = t
+	case []byte:
+		s = string(t)
+	default:
+		return scanDriverTime(v)
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02", time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unexpected date value %q", s)
+}
+
+// columnType 列定义中的类型名（大写），如 "date DATE /*...*/" 返回 DATE
+func columnType(colDef string) string {
+	fields := strings.Fields(colDef)
+	if len(fields) < 2 {
+		return ""
+	}
+	return strings.ToUpper(fields[1])
+}
+
+// replaceViewTx 在事务内删除并重建视图
+func replaceViewTx(db *sql.DB, name, query string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin create view %s: %w", name, err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("DROP VIEW IF EXISTS %s", name)); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to drop view %s: %w", name, err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("CREATE VIEW %s AS %s", name, query)); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to create view %s: %w", name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit create view %s: %w", name, err)
+	}
+	return nil
+}
@@ -4,71 +4,143 @@ import (
 	"database/sql"
 	"encoding/csv"
 	"fmt"
+	"math"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	_ "github.com/duckdb/duckdb-go/v2"
-	"github.com/jing2uo/tdx2db/model"
 	"github.com/jing2uo/tdx2db/tdx"
 )
 
+// raw_base 按快照日期保存 base.dbf，每次导入追加一个快照
 var BaseSchema = TableSchema{
-	Name: "raw_base",
-	Columns: []string{
+	Name:    "raw_base",
+	Columns: buildBaseColumns(),
+	Keys:    []string{"PRIMARY KEY (code, mkt, sdate)"},
+}
+
+func buildBaseColumns() []string {
+	columns := []string{
 		"code VARCHAR",
 		"mkt VARCHAR",
-		"zgb DOUBLE /*总股本*/",
-		"bg DOUBLE /*流通b股*/",
-		"hg DOUBLE /*流通h股*/",
-		"ltag DOUBLE /*流通A股*/",
-		"gdrs DOUBLE /*股东人数*/",
-		"ssdate DATE /*上市日期*/",
-		"mgjzc DOUBLE /*每股净资产*/",
-		"mgsy DOUBLE /*每股收益*/",
-
-		"yyzsr DOUBLE /*营业总收入 *1000*/",
-		"yycb DOUBLE /*营业成本 *1000*/",
-		"yylr DOUBLE /*营业利润 *1000*/",
-		"zlr DOUBLE /*利润总额*1000*/",
-		"jlr DOUBLE /*净利润*1000*/",
-		"gmjlr DOUBLE /*归母净利润*1000*/",
-
-		"jyxjl DOUBLE /*经营活动产生的现金流量净额 *1000*/",
-		"zxjl DOUBLE /*总现金流*1000*/",
-
-		"ldzc DOUBLE /*流动资产合计*1000*/",
-		"ch DOUBLE /*存货*1000*/",
-		"gdzc DOUBLE /*固定资产*1000*/",
-		"wxzc DOUBLE /*无形资产*1000*/",
-		"zzc DOUBLE /*总资产*1000*/",
-		"ldfz DOUBLE /*流动负债*1000*/",
-		"yszk DOUBLE /*应收账款*1000*/",
-
-		"jzc DOUBLE /*归母所有者权益*1000*/",
-		"cqfz DOUBLE /*少数股东权益*1000*/",
-		"wfplr DOUBLE /*未分配利润*1000*/",
-		"zbgjj DOUBLE /*资本公积金*1000*/",
-	},
+		"sdate DATE /*快照日期*/",
+	}
+
+	for _, column := range basebase {
+		comment := strings.ReplaceAll(column.desc, "*/", "")
+		columns = append(columns, fmt.Sprintf("%s %s /*%s*/", column.name, baseColumnType(column), comment))
+	}
+
+	return columns
 }
 
-var baseColumnNames = []string{
-	"code", "mkt", "zgb", "bg", "hg", "ltag", "gdrs", "ssdate", "mgjzc", "mgsy",
-	"yyzsr", "yycb", "yylr", "zlr", "jlr", "gmjlr", "jyxjl", "zxjl",
-	"ldzc", "ch", "gdzc", "wxzc", "zzc", "ldfz", "yszk",
-	"jzc", "cqfz", "wfplr", "zbgjj",
+func baseColumnType(column baseColumnDesc) string {
+	if column.typ == "" {
+		return "DOUBLE"
+	}
+	return column.typ
 }
 
-func ImportBase(db *sql.DB, recs []*model.DbfRecord) error {
-	//每次导入都重新建表
-	if err := DropTable(db, BaseSchema); err != nil {
-		return fmt.Errorf("failed to drop table: %w", err)
+// baseImportColumns 将 dbf 字段映射为列描述，未知字段按 dbf_ 加小写字段名生成，
+// 避免与已知字段的别名（如 CQTZ→gdrs）重名
+func baseImportColumns(fields []tdx.BaseDbfField) []baseColumnDesc {
+	known := make(map[string]baseColumnDesc, len(basebase))
+	for _, column := range basebase {
+		known[column.field] = column
+	}
+
+	columns := make([]baseColumnDesc, len(fields))
+	for i, field := range fields {
+		if column, ok := known[field.Name]; ok {
+			columns[i] = column
+			continue
+		}
+
+		column := baseColumnDesc{field: field.Name, name: "dbf_" + strings.ToLower(field.Name), typ: "VARCHAR", desc: "dbf " + field.Name}
+		if field.Numeric {
+			column.typ = "DOUBLE"
+		}
+		columns[i] = column
+	}
+	return columns
+}
+
+func formatBaseValue(column baseColumnDesc, raw string) string {
+	switch baseColumnType(column) {
+	case "DATE":
+		d, err := time.Parse("20060102", raw)
+		if err != nil {
+			return ""
+		}
+		return d.Format("2006-01-02")
+	case "DOUBLE":
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return ""
+		}
+		if column.scale != 0 {
+			v = math.Round(v*column.scale*1e6) / 1e6
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return raw
+	}
+}
+
+// ImportBase 导入 base.dbf 快照，同一快照日期重复导入时覆盖
+func ImportBase(db *sql.DB, dbf *tdx.BaseDbf) error {
+	existing, err := tableColumns(db, BaseSchema.Name)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 && !existing["sdate"] {
+		//旧版本的表没有快照日期，无法追加
+		fmt.Printf("⚠️ %s 缺少 sdate 列，重建表\n", BaseSchema.Name)
+		if err := DropTable(db, BaseSchema); err != nil {
+			return fmt.Errorf("failed to drop table: %w", err)
+		}
 	}
 
 	if err := CreateTable(db, BaseSchema); err != nil {
 		return fmt.Errorf("failed to create table: %w", err)
 	}
 
+	existing, err = tableColumns(db, BaseSchema.Name)
+	if err != nil {
+		return err
+	}
+
+	columns := baseImportColumns(dbf.Fields)
+	importSchema := TableSchema{
+		Name:    BaseSchema.Name,
+		Columns: []string{"code VARCHAR", "mkt VARCHAR", "sdate DATE"},
+	}
+	header := []string{"code", "mkt", "sdate"}
+	var valueIdx []int
+	for i, column := range columns {
+		if column.name == "code" || column.name == "mkt" || column.name == "sdate" {
+			continue
+		}
+		if !existing[column.name] {
+			query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s", BaseSchema.Name, column.name, baseColumnType(column))
+			if _, err := db.Exec(query); err != nil {
+				return fmt.Errorf("failed to add column %s: %w", column.name, err)
+			}
+			existing[column.name] = true
+			fmt.Printf("🆕 %s 新增列 %s (%s)\n", BaseSchema.Name, column.name, baseColumnType(column))
+		}
+		importSchema.Columns = append(importSchema.Columns, column.name+" "+baseColumnType(column))
+		header = append(header, column.name)
+		valueIdx = append(valueIdx, i)
+	}
+
+	sdate := dbf.Date.Format("2006-01-02")
+	if _, err := db.Exec(fmt.Sprintf("DELETE FROM %s WHERE sdate = ?", BaseSchema.Name), sdate); err != nil {
+		return fmt.Errorf("failed to delete snapshot %s: %w", sdate, err)
+	}
+
 	tmpFile, err := os.CreateTemp("", "tdx-base.csv")
 	if err != nil {
 		return fmt.Errorf("failed to create temp file: %w", err)
@@ -76,46 +148,23 @@ func ImportBase(db *sql.DB, recs []*model.DbfRecord) error {
 	defer os.Remove(tmpFile.Name())
 
 	writer := csv.NewWriter(tmpFile)
-	if err := writer.Write(baseColumnNames); err != nil {
+	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("failed to write CSV header: %w", err)
 	}
 
-	for _, record := range recs {
-		ssd, _ := time.Parse("20060102", fmt.Sprintf("%d", record.SSDATE))
-		row := make([]string, 0, len(baseColumnNames))
-		row = append(row, record.Code, record.Mkt,
-			strconv.FormatFloat(record.ZGB, 'f', -1, 32),
-			strconv.FormatFloat(record.BG, 'f', -1, 32),
-			strconv.FormatFloat(record.HG, 'f', -1, 32),
-			strconv.FormatFloat(record.LTAG, 'f', -1, 32),
-			strconv.Itoa(int(record.CQTZ)),
-			ssd.Format("2006-01-02"),
-			strconv.FormatFloat(record.TZMGJZ, 'f', -1, 32),
-			strconv.FormatFloat(record.ZGG, 'f', -1, 32),
-
-			strconv.FormatFloat(record.ZYSY, 'f', -1, 32),
-			strconv.FormatFloat(record.ZYLY, 'f', -1, 32),
-			strconv.FormatFloat(record.YYLY, 'f', -1, 32),
-			strconv.FormatFloat(record.LYZE, 'f', -1, 32),
-			strconv.FormatFloat(record.SHLY, 'f', -1, 32),
-			strconv.FormatFloat(record.JLY, 'f', -1, 32),
-
-			strconv.FormatFloat(record.BTSY, 'f', -1, 32),
-			strconv.FormatFloat(record.YYWSZ, 'f', -1, 32),
-
-			strconv.FormatFloat(record.LDZC, 'f', -1, 32),
-			strconv.FormatFloat(record.SNSYTZ, 'f', -1, 32),
-			strconv.FormatFloat(record.GDZC, 'f', -1, 32),
-			strconv.FormatFloat(record.WXZC, 'f', -1, 32),
-			strconv.FormatFloat(record.ZZC, 'f', -1, 32),
-			strconv.FormatFloat(record.LDFZ, 'f', -1, 32),
-			strconv.FormatFloat(record.QTLY, 'f', -1, 32),
-
-			strconv.FormatFloat(record.JZC, 'f', -1, 32),
-			strconv.FormatFloat(record.CQFZ, 'f', -1, 32),
-			strconv.FormatFloat(record.WFPLY, 'f', -1, 32),
-			strconv.FormatFloat(record.ZBGJJ, 'f', -1, 32),
-		)
+	seen := make(map[string]bool, len(dbf.Rows))
+	for _, record := range dbf.Rows {
+		key := record.Mkt + record.Code
+		if record.Code == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		row := make([]string, 0, len(header))
+		row = append(row, record.Code, record.Mkt, sdate)
+		for _, i := range valueIdx {
+			row = append(row, formatBaseValue(columns[i], record.Values[i]))
+		}
 
 		if err := writer.Write(row); err != nil {
 			return fmt.Errorf("failed to write CSV row for %s: %w", record.Code, err)
@@ -131,7 +180,7 @@ func ImportBase(db *sql.DB, recs []*model.DbfRecord) error {
 		return fmt.Errorf("failed to close temp CSV: %w", err)
 	}
 
-	if err := ImportCSV(db, BaseSchema, tmpFile.Name()); err != nil {
+	if err := ImportCSV(db, importSchema, tmpFile.Name()); err != nil {
 		return fmt.Errorf("failed to import CSV: %s %w", tmpFile.Name(), err)
 	}
 
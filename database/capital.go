@@ -0,0 +1,93 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jing2uo/tdx2db/model"
+	"github.com/jing2uo/tdx2db/tdx"
+)
+
+// 每个交易日的流通股本和总股本，由股本变迁中的股本变动类别按日展开
+var CapitalSchema = TableSchema{
+	Name: "raw_capital",
+	Columns: []string{
+		"symbol VARCHAR",
+		"date DATE",
+		"float_shares DOUBLE /*流通股本(万股)*/",
+		"total_shares DOUBLE /*总股本(万股)*/",
+	},
+	Keys: []string{"PRIMARY KEY (symbol, date)"},
+}
+
+// capitalEventsQuery 每个代码每天一条股本变动 (tdx.ShareChangeCategories)，同一天有多条时取总股本最大的一条
+func capitalEventsQuery() string {
+	return fmt.Sprintf(`
+		SELECT
+			code,
+			date,
+			ARG_MAX(c1, c4) AS prev_float_shares,
+			ARG_MAX(c2, c4) AS prev_total_shares,
+			ARG_MAX(c3, c4) AS float_shares,
+			MAX(c4) AS total_shares
+		FROM %s
+		WHERE category IN (%s)
+		GROUP BY code, date
+	`, GBBQSchema.Name, categoryIn(tdx.ShareChangeCategories))
+}
+
+// BuildCapital 重建 raw_capital：每个交易日取当日及之前最近一次股本变动后的股本，
+// 非交易日生效的变动也能落到之后的交易日。股本变迁或日线更新后调用
+func BuildCapital(db *sql.DB) error {
+	if err := DropTable(db, CapitalSchema); err != nil {
+		return fmt.Errorf("failed to drop table: %w", err)
+	}
+	if err := CreateTable(db, CapitalSchema); err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (symbol, date, float_shares, total_shares)
+		WITH cc AS (%s)
+		SELECT d.symbol, d.date, cc.float_shares, cc.total_shares
+		FROM (SELECT symbol, date, SUBSTR(symbol, 3) AS code FROM %s) d
+		ASOF JOIN cc ON d.code = cc.code AND d.date >= cc.date
+	`, CapitalSchema.Name, capitalEventsQuery(), StocksSchema.Name)
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to build capital: %w", err)
+	}
+	return nil
+}
+
+// QueryCapital 查询 code 的股本变动记录，按日期排序
+func QueryCapital(db *sql.DB, code string) ([]model.CapitalData, error) {
+	query := fmt.Sprintf(`
+		WITH cc AS (%s)
+		SELECT code, date, prev_float_shares, prev_total_shares, float_shares, total_shares
+		FROM cc
+		WHERE code = ?
+		ORDER BY date
+	`, capitalEventsQuery())
+
+	rows, err := db.Query(query, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query capital: %w", err)
+	}
+	defer rows.Close()
+
+	var results []model.CapitalData
+	for rows.Next() {
+		var c model.CapitalData
+		if err := rows.Scan(&c.Code, &c.Date, &c.PrevOutstanding, &c.PrevTotal, &c.Outstanding, &c.Total); err != nil {
+			return nil, fmt.Errorf("failed to scan capital data: %w", err)
+		}
+		results = append(results, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating capital data: %w", err)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+)
+
+// 日线、分时和复权因子中被修订、删除或重建的区间，export-lake 和 --sink 据此只处理变化的部分。
+// 追加在每只股票最新时间之后的行不记录，下游按行数和最新时间即可发现
+var ChangeLogSchema = TableSchema{
+	Name: "raw_change_log",
+	Columns: []string{
+		"version BIGINT /*变更批次，单调递增*/",
+		"table_name VARCHAR",
+		"symbol VARCHAR /*为 NULL 表示全部股票*/",
+		"from_time TIMESTAMP /*为 NULL 表示不限*/",
+		"to_time TIMESTAMP /*为 NULL 表示不限*/",
+		"changed_at TIMESTAMP",
+	},
+}
+
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// logChanges 把 ranges 查询返回的 (symbol, from_time, to_time) 记为同一批次的变更，区间两端均包含
+func logChanges(ctx context.Context, db sqlExecer, table, ranges string, args ...any) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (version, table_name, symbol, from_time, to_time, changed_at)
+		SELECT
+			(SELECT COALESCE(MAX(version), 0) + 1 FROM %[1]s),
+			'%[2]s',
+			symbol,
+			CAST(from_time AS TIMESTAMP),
+			CAST(to_time AS TIMESTAMP),
+			CAST(now() AS TIMESTAMP)
+		FROM (%[3]s)
+	`, ChangeLogSchema.Name, table, ranges)
+	if _, err := db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to log changes of %s: %w", table, err)
+	}
+	return nil
+}
+
+// logTableChanged 整表重建或全量重算
+func logTableChanged(db *sql.DB, table string) error {
+	if err := CreateTable(db, ChangeLogSchema); err != nil {
+		return err
+	}
+	return logChanges(context.Background(), db, table, "SELECT NULL AS symbol, NULL AS from_time, NULL AS to_time")
+}
+
+// logSymbolsChanged symbols 的全部历史都可能变化，如复权因子重算
+func logSymbolsChanged(db *sql.DB, table string, symbols []string) error {
+	if err := CreateTable(db, ChangeLogSchema); err != nil {
+		return err
+	}
+	for _, batch := range symbolBatches(symbols) {
+		ranges := fmt.Sprintf("SELECT UNNEST([%s]) AS symbol, NULL AS from_time, NULL AS to_time", batch)
+		if err := logChanges(context.Background(), db, table, ranges); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LatestChangeVersion 变更日志当前的最大批次，没有变更日志时为 0
+func LatestChangeVersion(db *sql.DB) (int64, error) {
+	cols, err := tableColumns(db, ChangeLogSchema.Name)
+	if err != nil {
+		return 0, err
+	}
+	if len(cols) == 0 {
+		return 0, nil
+	}
+
+	var version sql.NullInt64
+	query := fmt.Sprintf("SELECT MAX(version) FROM %s", ChangeLogSchema.Name)
+	if err := db.QueryRow(query).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to query change log version: %w", err)
+	}
+	return version.Int64, nil
+}
+
+// changedRowsFilter 别名 t 的行落在 (since, upto] 批次内某个变更区间时为真
+func changedRowsFilter(table, timeCol string, since, upto int64) string {
+	return fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM %[1]s c
+		WHERE c.table_name = '%[2]s' AND c.version > %[4]d AND c.version <= %[5]d
+		  AND (c.symbol IS NULL OR c.symbol = t.symbol)
+		  AND (c.from_time IS NULL OR t.%[3]s >= c.from_time)
+		  AND (c.to_time IS NULL OR t.%[3]s <= c.to_time)
+	)`, ChangeLogSchema.Name, table, timeCol, since, upto)
+}
+
+// tableRebuilt (since, upto] 批次内 table 是否整表重建或全量重算
+func tableRebuilt(db *sql.DB, table string, since, upto int64) (bool, error) {
+	var n int64
+	query := fmt.Sprintf(`
+		SELECT COUNT(*) FROM %s
+		WHERE table_name = '%s' AND version > %d AND version <= %d
+		  AND symbol IS NULL AND from_time IS NULL AND to_time IS NULL
+	`, ChangeLogSchema.Name, table, since, upto)
+	if err := db.QueryRow(query).Scan(&n); err != nil {
+		return false, fmt.Errorf("failed to query change log of %s: %w", table, err)
+	}
+	return n > 0, nil
+}
+
+// changeRangesQuery (since, upto] 批次内 table 的变更区间，区间两端转为 timeType 以便与时间列比较
+func changeRangesQuery(table, timeType string, since, upto int64) string {
+	return fmt.Sprintf(`
+		SELECT DISTINCT symbol, CAST(from_time AS %[3]s), CAST(to_time AS %[3]s)
+		FROM %[1]s
+		WHERE table_name = '%[2]s' AND version > %[4]d AND version <= %[5]d
+	`, ChangeLogSchema.Name, table, timeType, since, upto)
+}
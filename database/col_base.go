@@ -0,0 +1,47 @@
+package database
+
+type baseColumnDesc struct {
+	field string // dbf 字段名
+	name  string // 列名
+	typ   string // 列类型，为空表示 DOUBLE
+	desc  string
+	scale float64 // 入库时乘以的系数，0 表示不换算
+}
+
+// base.dbf 字段描述，未列出的字段导入时按小写字段名自动加列
+var basebase = []baseColumnDesc{
+	{field: "ZGB", name: "zgb", desc: "总股本", scale: 10000},
+	{field: "BG", name: "bg", desc: "流通b股", scale: 10000},
+	{field: "HG", name: "hg", desc: "流通h股", scale: 10000},
+	{field: "LTAG", name: "ltag", desc: "流通A股", scale: 10000},
+	{field: "CQTZ", name: "gdrs", desc: "股东人数"},
+	{field: "SSDATE", name: "ssdate", typ: "DATE", desc: "上市日期"},
+	{field: "TZMGJZ", name: "mgjzc", desc: "每股净资产"},
+	{field: "ZGG", name: "mgsy", desc: "每股收益"},
+
+	//利润表
+	{field: "ZYSY", name: "yyzsr", desc: "营业总收入 *1000", scale: 1000},
+	{field: "ZYLY", name: "yycb", desc: "营业成本 *1000", scale: 1000},
+	{field: "YYLY", name: "yylr", desc: "营业利润 *1000", scale: 1000},
+	{field: "LYZE", name: "zlr", desc: "利润总额*1000", scale: 1000},
+	{field: "SHLY", name: "jlr", desc: "净利润*1000", scale: 1000},
+	{field: "JLY", name: "gmjlr", desc: "归母净利润*1000", scale: 1000},
+
+	//现金流量表
+	{field: "BTSY", name: "jyxjl", desc: "经营活动产生的现金流量净额 *1000", scale: 1000},
+	{field: "YYWSZ", name: "zxjl", desc: "总现金流*1000", scale: 1000}, //经营现金流+投资现金流+筹资现金流
+
+	//资产负债表 ldzc+fldzc(自己算)=zzc=ldfz+fldfx(自己算)+jzc+cqfz
+	{field: "LDZC", name: "ldzc", desc: "流动资产合计*1000", scale: 1000},
+	{field: "SNSYTZ", name: "ch", desc: "存货*1000", scale: 1000},
+	{field: "GDZC", name: "gdzc", desc: "固定资产*1000", scale: 1000},
+	{field: "WXZC", name: "wxzc", desc: "无形资产*1000", scale: 1000},
+	{field: "ZZC", name: "zzc", desc: "总资产*1000", scale: 1000},
+	{field: "LDFZ", name: "ldfz", desc: "流动负债*1000", scale: 1000},
+	{field: "QTLY", name: "yszk", desc: "应收账款*1000", scale: 1000},
+
+	{field: "JZC", name: "jzc", desc: "归母所有者权益*1000", scale: 1000},
+	{field: "CQFZ", name: "cqfz", desc: "少数股东权益*1000", scale: 1000},
+	{field: "WFPLY", name: "wfplr", desc: "未分配利润*1000", scale: 1000},
+	{field: "ZBGJJ", name: "zbgjj", desc: "资本公积金*1000", scale: 1000},
+}
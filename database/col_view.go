@@ -1,5 +1,10 @@
 package database
 
+import (
+	"fmt"
+	"strings"
+)
+
 type ColumnView struct {
 	name  string
 	alias string
@@ -12,3 +17,25 @@ type ColumnViews struct {
 	from   string
 	desc   string
 }
+
+// viewDDL 视图定义，每个字段一行，desc 写成行内注释
+func viewDDL(view ColumnViews) string {
+	columns := make([]string, 0, len(view.fields))
+	for _, field := range view.fields {
+		columns = append(columns, formatColumn(field))
+	}
+	return fmt.Sprintf("CREATE OR REPLACE VIEW %s AS\nSELECT\n    %s\nFROM %s;",
+		view.name, strings.Join(columns, ",\n    "), view.from)
+}
+
+func formatColumn(field ColumnView) string {
+	expr := field.name
+	if field.alias != "" {
+		expr = fmt.Sprintf("%s AS %s", field.name, field.alias)
+	}
+	if field.desc != "" {
+		clean := strings.ReplaceAll(field.desc, "*/", "")
+		expr = fmt.Sprintf("%s /* %s */", expr, clean)
+	}
+	return expr
+}
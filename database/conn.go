@@ -33,17 +33,14 @@ type TableSchema struct {
 	Keys    []string
 }
 
+// tableDDL 建表语句，每列一行，CreateTable 和 dump-schema 共用
+func tableDDL(schema TableSchema) string {
+	lines := append(append([]string{}, schema.Columns...), schema.Keys...)
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n    %s\n);", schema.Name, strings.Join(lines, ",\n    "))
+}
+
 func CreateTable(db *sql.DB, schema TableSchema) error {
-	columnsStr := strings.Join(schema.Columns, ", ")
-	if len(schema.Keys) > 0 {
-		kstr := strings.Join(schema.Keys, ", ")
-		columnsStr = columnsStr + ", " + kstr
-	}
-	query := fmt.Sprintf(`
-		CREATE TABLE IF NOT EXISTS %s (
-			%s
-		)
-	`, schema.Name, columnsStr)
+	query := tableDDL(schema)
 
 	_, err := db.Exec(query)
 	if err != nil {
@@ -124,3 +121,22 @@ func GetLatestDateFromTable(db *sql.DB, tableName string) (time.Time, error) {
 
 	return time.Time{}, nil
 }
+
+// tableColumns 返回表中已有的列名，表不存在时返回空集合
+func tableColumns(db *sql.DB, tableName string) (map[string]bool, error) {
+	rows, err := db.Query(`SELECT column_name FROM information_schema.columns WHERE table_name = ?`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns of %s: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan column of %s: %w", tableName, err)
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
@@ -2,13 +2,23 @@ package database
 
 import "database/sql"
 
+// cwSecurityFrom 财务数据只有 6 位代码，按股票类型关联 raw_security 取名称和类型，沪深京股票代码互不重复
+const cwSecurityFrom = `raw_caiwu
+LEFT JOIN (
+    SELECT code AS sec_code, name, subtype
+    FROM raw_security
+    WHERE subtype IN ('ashare', 'bshare', 'stock')
+) sec ON raw_caiwu.code = sec.sec_code`
+
 var cwViews = []ColumnViews{
 	{
 		name: "v_cw_stmt_core",
-		from: "raw_caiwu",
+		from: cwSecurityFrom,
 		desc: "1. 三大报表原始+单季核心",
 		fields: []ColumnView{
 			{name: "code", desc: "证券代码"},
+			{name: "name", desc: "证券名称"},
+			{name: "subtype", desc: "证券类型"},
 			{name: "report_date", alias: "rdate", desc: "报告期"},
 			{name: "announce_date", alias: "adate", desc: "公告日期"},
 			{name: "f0", alias: "eps_basic", desc: "基本每股收益"},
@@ -199,10 +209,12 @@ var cwViews = []ColumnViews{
 	},
 	{
 		name: "v_cw_ratio_quality",
-		from: "raw_caiwu",
+		from: cwSecurityFrom,
 		desc: "2. 现金流结构与质量 比率、盈利质量、成长性 因子",
 		fields: []ColumnView{
 			{name: "code", desc: "证券代码"},
+			{name: "name", desc: "证券名称"},
+			{name: "subtype", desc: "证券类型"},
 			{name: "report_date", alias: "rdate", desc: "报告期"},
 			{name: "announce_date", alias: "adate", desc: "公告日期"},
 			{name: "f158", alias: "current_ratio", desc: "流动比率"},
@@ -272,10 +284,12 @@ var cwViews = []ColumnViews{
 	},
 	{
 		name: "v_cw_cashflow_structure",
-		from: "raw_caiwu",
+		from: cwSecurityFrom,
 		desc: "3. 现金流结构与质量 聚焦于“现金流 vs 利润 vs 收入”",
 		fields: []ColumnView{
 			{name: "code", desc: "证券代码"},
+			{name: "name", desc: "证券名称"},
+			{name: "subtype", desc: "证券类型"},
 			{name: "report_date", alias: "rdate", desc: "报告期"},
 			{name: "announce_date", alias: "adate", desc: "公告日期"},
 			{name: "f218", alias: "operating_cf_per_share", desc: "每股经营性现金流(元)"},
@@ -299,10 +313,12 @@ var cwViews = []ColumnViews{
 	},
 	{
 		name: "v_cw_holding_structure",
-		from: "raw_caiwu",
+		from: cwSecurityFrom,
 		desc: "4. 股本结构 & 股东/机构持股",
 		fields: []ColumnView{
 			{name: "code", desc: "证券代码"},
+			{name: "name", desc: "证券名称"},
+			{name: "subtype", desc: "证券类型"},
 			{name: "report_date", alias: "rdate", desc: "报告期"},
 			{name: "announce_date", alias: "adate", desc: "公告日期"},
 			{name: "f237", alias: "total_shares", desc: "总股本"},
@@ -351,10 +367,12 @@ var cwViews = []ColumnViews{
 	},
 	{
 		name: "v_cw_event_forecast",
-		from: "raw_caiwu",
+		from: cwSecurityFrom,
 		desc: "5. 预告 / 快报 / 公告事件",
 		fields: []ColumnView{
 			{name: "code", desc: "证券代码"},
+			{name: "name", desc: "证券名称"},
+			{name: "subtype", desc: "证券类型"},
 			{name: "report_date", alias: "rdate", desc: "报告期"},
 			{name: "announce_date", alias: "adate", desc: "公告日期"},
 			{name: "f284", alias: "guidance_net_profit_yoy_low", desc: "本期净利润同比增幅下限(%)"},
@@ -383,10 +401,12 @@ var cwViews = []ColumnViews{
 	},
 	{
 		name: "v_cw_industry_ext",
-		from: "raw_caiwu",
+		from: cwSecurityFrom,
 		desc: "6. 金融/保险/券商行业专属扩展",
 		fields: []ColumnView{
 			{name: "code", desc: "证券代码"},
+			{name: "name", desc: "证券名称"},
+			{name: "subtype", desc: "证券类型"},
 			{name: "report_date", alias: "rdate", desc: "报告期"},
 			{name: "announce_date", alias: "adate", desc: "公告日期"},
 			{name: "f401", alias: "settlement_reserve", desc: "结算备付金"},
@@ -459,10 +479,12 @@ var cwViews = []ColumnViews{
 	},
 	{
 		name: "v_cw_factor_input",
-		from: "raw_caiwu",
+		from: cwSecurityFrom,
 		desc: "7. 财务因子库",
 		fields: []ColumnView{
 			{name: "code", desc: "证券代码"},
+			{name: "name", desc: "证券名称"},
+			{name: "subtype", desc: "证券类型"},
 			{name: "report_date", alias: "rdate", desc: "报告期"},
 			{name: "announce_date", alias: "adate", desc: "公告日期"},
 			{name: "f0", alias: "eps_basic", desc: "基本每股收益"},
@@ -550,6 +572,9 @@ var cwViews = []ColumnViews{
 }
 
 func CreateCwViews(db *sql.DB) error {
+	if err := CreateTable(db, SecuritySchema); err != nil {
+		return err
+	}
 	for _, view := range cwViews {
 		if err := createView(db, view); err != nil {
 			return err
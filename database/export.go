@@ -0,0 +1,89 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jing2uo/tdx2db/tdx"
+)
+
+// barsQuery 将表名或查询语句包装为按 symbol、时间排序的 K 线查询。
+// 结果需包含 symbol, open, high, low, close, amount, volume 以及 timeCol 列
+func barsQuery(table, query, timeCol string) string {
+	from := table
+	if query != "" {
+		from = fmt.Sprintf("(%s)", query)
+	}
+	return fmt.Sprintf(`
+		SELECT symbol, open, high, low, close, amount, volume, %[1]s
+		FROM %[2]s
+		WHERE open IS NOT NULL AND close IS NOT NULL
+		ORDER BY symbol, %[1]s
+	`, timeCol, from)
+}
+
+func streamBars(db *sql.DB, query string, handle func(symbol string, open, high, low, close, amount, volume float64, ts time.Time) error) error {
+	rows, err := db.Query(query)
+	if err != nil {
+		return fmt.Errorf("failed to query bars: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var symbol string
+		var open, high, low, close float64
+		var amount, volume sql.NullFloat64
+		var ts time.Time
+		if err := rows.Scan(&symbol, &open, &high, &low, &close, &amount, &volume, &ts); err != nil {
+			return fmt.Errorf("failed to scan bar: %w", err)
+		}
+		if err := handle(symbol, open, high, low, close, amount.Float64, volume.Float64, ts); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating bars: %w", err)
+	}
+	return nil
+}
+
+// StreamDayBars 按 symbol、date 顺序读取日线，table 与 query 二选一，table 为空时默认 raw_stocks_daily
+func StreamDayBars(db *sql.DB, table, query string, handle func(tdx.DayKlineRecord) error) error {
+	if table == "" && query == "" {
+		table = StocksSchema.Name
+	}
+	return streamBars(db, barsQuery(table, query, "date"), func(symbol string, open, high, low, close, amount, volume float64, ts time.Time) error {
+		return handle(tdx.DayKlineRecord{
+			Symbol: symbol,
+			Open:   open,
+			High:   high,
+			Low:    low,
+			Close:  close,
+			Amount: amount,
+			Volume: int64(math.Round(volume)),
+			Date:   ts,
+		})
+	})
+}
+
+// StreamMinBars 按 symbol、datetime 顺序读取分钟线，table 与 query 二选一
+func StreamMinBars(db *sql.DB, table, query string, handle func(tdx.MinKlineRecord) error) error {
+	if table == "" && query == "" {
+		return fmt.Errorf("table or query is required")
+	}
+	return streamBars(db, barsQuery(table, query, "datetime"), func(symbol string, open, high, low, close, amount, volume float64, ts time.Time) error {
+		return handle(tdx.MinKlineRecord{
+			Symbol:   symbol,
+			Open:     open,
+			High:     high,
+			Low:      low,
+			Close:    close,
+			Amount:   amount,
+			Volume:   int64(math.Round(volume)),
+			Datetime: ts,
+		})
+	})
+}
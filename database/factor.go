@@ -3,8 +3,10 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
 	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jing2uo/tdx2db/tdx"
 )
 
 // 预定义表结构
@@ -18,22 +20,220 @@ var FactorSchema = TableSchema{
 		"qfq_factor DOUBLE",
 		"hfq_factor DOUBLE",
 	},
+	Keys: []string{"PRIMARY KEY (symbol, date)"},
 }
 
-// 使用示例
-func ImportFactorCsv(db *sql.DB, csvPath string) error {
-	//每次导入都重新建表
-	if err := DropTable(db, FactorSchema); err != nil {
-		return fmt.Errorf("failed to drop table: %w", err)
+// 每只股票上次计算复权因子时的状态，用于判断本次是否需要重算
+var FactorStateSchema = TableSchema{
+	Name: "raw_adjust_factor_state",
+	Columns: []string{
+		"symbol VARCHAR",
+		"last_date DATE /*已计算到的最后交易日*/",
+		"bar_count BIGINT /*已计算的交易日数*/",
+		"xdxr_sig VARCHAR /*除权除息记录签名*/",
+		"last_close DOUBLE",
+		"last_hfq DOUBLE",
+	},
+	Keys: []string{"PRIMARY KEY (symbol)"},
+}
+
+// FactorPlan 本次复权因子更新计划
+type FactorPlan struct {
+	Recompute []string // 除权除息有变化、新事件落在新数据区间或历史数据有补录，需要全量重算
+	Extend    []string // 只有新增交易日，沿用最后的后复权因子追加
+}
+
+// ResetFactors 清空复权因子及状态表，下次按全量计算
+func ResetFactors(db *sql.DB) error {
+	for _, schema := range []TableSchema{FactorSchema, FactorStateSchema} {
+		if err := DropTable(db, schema); err != nil {
+			return fmt.Errorf("failed to drop table: %w", err)
+		}
+	}
+	if err := ensureFactorTables(db); err != nil {
+		return err
+	}
+	return logTableChanged(db, FactorSchema.Name)
+}
+
+func ensureFactorTables(db *sql.DB) error {
+	for _, schema := range []TableSchema{FactorSchema, FactorStateSchema} {
+		if err := CreateTable(db, schema); err != nil {
+			return fmt.Errorf("failed to create table: %w", err)
+		}
+	}
+	return nil
+}
+
+// FactorStateEmpty 状态表不存在或为空时返回 true，此时需要全量计算
+func FactorStateEmpty(db *sql.DB) (bool, error) {
+	columns, err := tableColumns(db, FactorStateSchema.Name)
+	if err != nil {
+		return false, err
+	}
+	if len(columns) == 0 {
+		return true, nil
 	}
 
-	// 创建表
-	if err := CreateTable(db, FactorSchema); err != nil {
-		return fmt.Errorf("failed to create table: %w", err)
+	var n int64
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", FactorStateSchema.Name)
+	if err := db.QueryRow(query).Scan(&n); err != nil {
+		return false, fmt.Errorf("failed to count %s: %w", FactorStateSchema.Name, err)
+	}
+	return n == 0, nil
+}
+
+// xdxrSigQuery 每个代码影响复权因子的事件 (除权除息、扩缩股) 签名及最后事件日期
+func xdxrSigQuery() string {
+	return fmt.Sprintf(`
+		SELECT
+			code,
+			md5(string_agg(concat_ws(',', date, c1, c2, c3, c4), ';' ORDER BY date, c1, c2, c3, c4)) AS sig,
+			MAX(date) AS last_event
+		FROM %s
+		WHERE category IN (%s)
+		GROUP BY code
+	`, GBBQSchema.Name, categoryIn(tdx.XdxrCategories))
+}
+
+// PlanFactorUpdate 对比日线、除权除息与状态表，得出需要重算和追加的股票。
+// 事件日晚于已计算日期时，等日线到达事件日才重算，提前公布的事件不会每次都触发重算
+func PlanFactorUpdate(db *sql.DB) (*FactorPlan, error) {
+	if err := ensureFactorTables(db); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		WITH bars AS (
+			SELECT
+				s.symbol,
+				MAX(s.date) AS max_date,
+				COUNT(*) FILTER (WHERE st.last_date IS NOT NULL AND s.date <= st.last_date) AS old_count
+			FROM %[1]s s
+			LEFT JOIN %[2]s st ON st.symbol = s.symbol
+			GROUP BY s.symbol
+		),
+		xdxr AS (%[3]s)
+		SELECT
+			b.symbol,
+			CASE
+				WHEN st.symbol IS NULL THEN 'recompute'
+				WHEN st.xdxr_sig IS DISTINCT FROM x.sig THEN 'recompute'
+				WHEN x.last_event > st.last_date AND x.last_event <= b.max_date THEN 'recompute'
+				WHEN b.old_count <> st.bar_count THEN 'recompute'
+				WHEN b.max_date > st.last_date THEN 'extend'
+				ELSE 'none'
+			END AS action
+		FROM bars b
+		LEFT JOIN %[2]s st ON st.symbol = b.symbol
+		LEFT JOIN xdxr x ON x.code = SUBSTR(b.symbol, 3)
+	`, StocksSchema.Name, FactorStateSchema.Name, xdxrSigQuery())
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan factor update: %w", err)
+	}
+	defer rows.Close()
+
+	plan := &FactorPlan{}
+	for rows.Next() {
+		var symbol, action string
+		if err := rows.Scan(&symbol, &action); err != nil {
+			return nil, fmt.Errorf("failed to scan factor plan: %w", err)
+		}
+		switch action {
+		case "recompute":
+			plan.Recompute = append(plan.Recompute, symbol)
+		case "extend":
+			plan.Extend = append(plan.Extend, symbol)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating factor plan: %w", err)
+	}
+
+	return plan, nil
+}
+
+// ReplaceFactors 删除 symbols 的旧因子后导入 CSV 中重算的结果
+func ReplaceFactors(db *sql.DB, symbols []string, csvPath string) error {
+	if err := ensureFactorTables(db); err != nil {
+		return err
+	}
+
+	for _, batch := range symbolBatches(symbols) {
+		query := fmt.Sprintf("DELETE FROM %s WHERE symbol IN (%s)", FactorSchema.Name, batch)
+		if _, err := db.Exec(query); err != nil {
+			return fmt.Errorf("failed to delete factors: %w", err)
+		}
 	}
 
 	if err := ImportCSV(db, FactorSchema, csvPath); err != nil {
 		return fmt.Errorf("failed to import CSV: %w", err)
 	}
+	return logSymbolsChanged(db, FactorSchema.Name, symbols)
+}
+
+// ExtendFactors 为没有新除权除息的股票追加新交易日的因子：
+// 前收盘价为上一交易日收盘价，前复权因子为 1，后复权因子沿用最后一天
+func ExtendFactors(db *sql.DB, symbols []string) error {
+	for _, batch := range symbolBatches(symbols) {
+		query := fmt.Sprintf(`
+			INSERT OR REPLACE INTO %[1]s (symbol, date, close, pre_close, qfq_factor, hfq_factor)
+			SELECT
+				s.symbol,
+				s.date,
+				s.close,
+				COALESCE(LAG(s.close) OVER (PARTITION BY s.symbol ORDER BY s.date), st.last_close),
+				1.0,
+				st.last_hfq
+			FROM %[2]s s
+			JOIN %[3]s st ON st.symbol = s.symbol
+			WHERE s.symbol IN (%[4]s) AND s.date > st.last_date
+		`, FactorSchema.Name, StocksSchema.Name, FactorStateSchema.Name, batch)
+		if _, err := db.Exec(query); err != nil {
+			return fmt.Errorf("failed to extend factors: %w", err)
+		}
+	}
+	return nil
+}
+
+// UpdateFactorState 根据 raw_adjust_factor 刷新 symbols 的计算状态
+func UpdateFactorState(db *sql.DB, symbols []string) error {
+	for _, batch := range symbolBatches(symbols) {
+		query := fmt.Sprintf(`
+			INSERT OR REPLACE INTO %[1]s (symbol, last_date, bar_count, xdxr_sig, last_close, last_hfq)
+			WITH xdxr AS (%[2]s)
+			SELECT
+				f.symbol,
+				MAX(f.date),
+				COUNT(*),
+				ANY_VALUE(x.sig),
+				ARG_MAX(f.close, f.date),
+				ARG_MAX(f.hfq_factor, f.date)
+			FROM %[3]s f
+			LEFT JOIN xdxr x ON x.code = SUBSTR(f.symbol, 3)
+			WHERE f.symbol IN (%[4]s)
+			GROUP BY f.symbol
+		`, FactorStateSchema.Name, xdxrSigQuery(), FactorSchema.Name, batch)
+		if _, err := db.Exec(query); err != nil {
+			return fmt.Errorf("failed to update factor state: %w", err)
+		}
+	}
 	return nil
 }
+
+// symbolBatches 将股票代码拼成 IN 列表，每批 1000 个
+func symbolBatches(symbols []string) []string {
+	const size = 1000
+	var res []string
+	for start := 0; start < len(symbols); start += size {
+		end := min(start+size, len(symbols))
+		quoted := make([]string, 0, end-start)
+		for _, s := range symbols[start:end] {
+			quoted = append(quoted, "'"+strings.ReplaceAll(s, "'", "''")+"'")
+		}
+		res = append(res, strings.Join(quoted, ", "))
+	}
+	return res
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+)
+
+var FactorEventsViewName = "v_factor_events"
+
+func factorEventsViewSQL() string {
+	return fmt.Sprintf(`
+	CREATE OR REPLACE VIEW %[1]s AS
+	WITH f AS (
+		SELECT
+			symbol,
+			date,
+			close,
+			pre_close,
+			hfq_factor,
+			LAG(date) OVER w AS prev_date,
+			LAG(close) OVER w AS prev_close,
+			LAG(hfq_factor) OVER w AS prev_hfq
+		FROM %[2]s
+		WINDOW w AS (PARTITION BY symbol ORDER BY date)
+	),
+	changed AS (
+		SELECT * FROM f
+		WHERE prev_hfq IS NOT NULL AND hfq_factor <> prev_hfq
+	)
+	SELECT
+		c.symbol,
+		c.date,
+		c.prev_close,
+		c.pre_close,
+		c.close,
+		ROUND(c.hfq_factor / NULLIF(c.prev_hfq, 0), 6) AS factor_step,
+		ROUND(c.close / NULLIF(c.prev_close, 0) - 1, 4) AS raw_change,
+		ROUND(c.close * c.hfq_factor / NULLIF(c.prev_close * c.prev_hfq, 0) - 1, 4) AS adj_change,
+		SUM(x.fenhong) AS fenhong,
+		SUM(x.songzhuangu) AS songzhuangu,
+		SUM(x.peigu) AS peigu,
+		MAX(x.peigujia) AS peigujia,
+		PRODUCT(x.suogu) AS suogu,
+		string_agg(
+			concat_ws(' ', strftime(x.date, '%%Y-%%m-%%d'),
+				CASE WHEN x.fenhong <> 0 THEN '派' || x.fenhong END,
+				CASE WHEN x.songzhuangu <> 0 THEN '送转' || x.songzhuangu END,
+				CASE WHEN x.peigu <> 0 THEN '配' || x.peigu || '@' || x.peigujia END,
+				CASE WHEN x.suogu <> 1 THEN '扩缩股' || x.suogu END),
+			'; ' ORDER BY x.date
+		) AS events
+	FROM changed c
+	LEFT JOIN %[3]s x
+		ON x.code = SUBSTR(c.symbol, 3) AND x.date > c.prev_date AND x.date <= c.date
+	GROUP BY ALL;
+	`, FactorEventsViewName, FactorSchema.Name, XdxrViewName)
+}
+
+// CreateFactorEventsView 列出复权因子发生变化的交易日及期间的除权除息、扩缩股事件。
+// raw_change 为不复权涨跌幅，adj_change 为复权后涨跌幅，两者相差较大说明跌幅来自除权或缩股而非真实下跌
+func CreateFactorEventsView(db *sql.DB) error {
+	query := factorEventsViewSQL()
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to create or replace view %s: %w", FactorEventsViewName, err)
+	}
+	return nil
+}
+
+// FactorEvent 一次影响复权因子的事件
+type FactorEvent struct {
+	Symbol     string
+	Date       time.Time
+	PrevClose  float64
+	PreClose   float64
+	Close      float64
+	FactorStep float64
+	RawChange  float64
+	AdjChange  float64
+	Events     string
+}
+
+// QueryFactorEvents 查询 symbol 影响复权因子的事件，按日期排序
+func QueryFactorEvents(db *sql.DB, symbol string) ([]FactorEvent, error) {
+	query := fmt.Sprintf(`
+		SELECT symbol, date, prev_close, pre_close, close, factor_step,
+			COALESCE(raw_change, 0), COALESCE(adj_change, 0), COALESCE(events, '')
+		FROM %s
+		WHERE symbol = ?
+		ORDER BY date
+	`, FactorEventsViewName)
+
+	rows, err := db.Query(query, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query factor events: %w", err)
+	}
+	defer rows.Close()
+
+	var results []FactorEvent
+	for rows.Next() {
+		var e FactorEvent
+		if err := rows.Scan(&e.Symbol, &e.Date, &e.PrevClose, &e.PreClose, &e.Close,
+			&e.FactorStep, &e.RawChange, &e.AdjChange, &e.Events); err != nil {
+			return nil, fmt.Errorf("failed to scan factor event: %w", err)
+		}
+		results = append(results, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating factor events: %w", err)
+	}
+
+	return results, nil
+}
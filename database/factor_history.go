@@ -0,0 +1,183 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jing2uo/tdx2db/model"
+)
+
+// 复权因子的历史版本，只记录相对上一版本有变化的行，
+// 某日看到的因子为 asof_date 不晚于该日的最新版本
+var FactorHistorySchema = TableSchema{
+	Name: "raw_adjust_factor_history",
+	Columns: []string{
+		"symbol VARCHAR",
+		"date DATE",
+		"asof_date DATE /*计算时日线的最新交易日*/",
+		"pre_close DOUBLE",
+		"qfq_factor DOUBLE",
+		"hfq_factor DOUBLE",
+	},
+	Keys: []string{"PRIMARY KEY (symbol, date, asof_date)"},
+}
+
+// FactorAsofMacroName 某日看到的复权因子：adjust_factor_asof(day)
+var FactorAsofMacroName = "adjust_factor_asof"
+
+// QfqAsofMacroName 某日看到的前复权日线：qfq_stocks_asof(day)
+var QfqAsofMacroName = "qfq_stocks_asof"
+
+// SnapshotFactors 把 raw_adjust_factor 中与最新版本不同的行记为 asof 日的版本，asof 取计算时
+// raw_stocks_daily 的最新交易日。symbols 为本次更新过因子的股票；历史表为空时对全部股票建立首个版本
+func SnapshotFactors(db *sql.DB, asof time.Time, symbols []string) error {
+	if err := CreateTable(db, FactorHistorySchema); err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	var n int64
+	query := fmt.Sprintf("SELECT COUNT(*) FROM (SELECT 1 FROM %s LIMIT 1)", FactorHistorySchema.Name)
+	if err := db.QueryRow(query).Scan(&n); err != nil {
+		return fmt.Errorf("failed to count %s: %w", FactorHistorySchema.Name, err)
+	}
+
+	filters := []string{"TRUE"}
+	if n > 0 {
+		filters = nil
+		for _, batch := range symbolBatches(symbols) {
+			filters = append(filters, fmt.Sprintf("symbol IN (%s)", batch))
+		}
+	}
+
+	day := asof.Format("2006-01-02")
+	for _, filter := range filters {
+		// 同一天重复计算时以最后一次为准
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s AND asof_date = DATE '%s'", FactorHistorySchema.Name, filter, day)
+		if _, err := db.Exec(query); err != nil {
+			return fmt.Errorf("failed to delete factor snapshot: %w", err)
+		}
+
+		query = fmt.Sprintf(`
+			INSERT INTO %[1]s (symbol, date, asof_date, pre_close, qfq_factor, hfq_factor)
+			WITH latest AS (
+				SELECT
+					symbol,
+					date,
+					ARG_MAX(pre_close, asof_date) AS pre_close,
+					ARG_MAX(qfq_factor, asof_date) AS qfq_factor,
+					ARG_MAX(hfq_factor, asof_date) AS hfq_factor
+				FROM %[1]s
+				WHERE %[3]s AND asof_date < DATE '%[4]s'
+				GROUP BY symbol, date
+			)
+			SELECT f.symbol, f.date, DATE '%[4]s', f.pre_close, f.qfq_factor, f.hfq_factor
+			FROM (SELECT * FROM %[2]s WHERE %[3]s) f
+			LEFT JOIN latest l ON l.symbol = f.symbol AND l.date = f.date
+			WHERE l.symbol IS NULL
+			   OR l.pre_close IS DISTINCT FROM f.pre_close
+			   OR l.qfq_factor IS DISTINCT FROM f.qfq_factor
+			   OR l.hfq_factor IS DISTINCT FROM f.hfq_factor
+		`, FactorHistorySchema.Name, FactorSchema.Name, filter, day)
+		if _, err := db.Exec(query); err != nil {
+			return fmt.Errorf("failed to snapshot factors: %w", err)
+		}
+	}
+	return nil
+}
+
+func factorAsofMacroSQL() string {
+	return fmt.Sprintf(`
+	CREATE OR REPLACE MACRO %[1]s(day) AS TABLE
+	SELECT
+		symbol,
+		date,
+		ARG_MAX(pre_close, asof_date) AS pre_close,
+		ARG_MAX(qfq_factor, asof_date) AS qfq_factor,
+		ARG_MAX(hfq_factor, asof_date) AS hfq_factor
+	FROM %[2]s
+	WHERE asof_date <= CAST(day AS DATE) AND date <= CAST(day AS DATE)
+	GROUP BY symbol, date;
+	`, FactorAsofMacroName, FactorHistorySchema.Name)
+}
+
+func qfqAsofMacroSQL() string {
+	return fmt.Sprintf(`
+	CREATE OR REPLACE MACRO %[1]s(day) AS TABLE
+	SELECT
+		s.symbol,
+		s.date,
+		CAST(ROUND(s.volume / NULLIF(f.qfq_factor, 0)) AS BIGINT) AS volume,
+		s.amount,
+		ROUND(s.open  * f.qfq_factor, 4) AS open,
+		ROUND(s.high  * f.qfq_factor, 4) AS high,
+		ROUND(s.low   * f.qfq_factor, 4) AS low,
+		ROUND(s.close * f.qfq_factor, 4) AS close
+	FROM %[2]s s
+	JOIN %[3]s(day) f ON s.symbol = f.symbol AND s.date = f.date;
+	`, QfqAsofMacroName, StocksSchema.Name, FactorAsofMacroName)
+}
+
+// CreateFactorAsofMacros 创建按日期回看的因子和前复权表宏，结果只包含当日及之前的交易日：
+//
+//	SELECT * FROM qfq_stocks_asof(DATE '2024-06-28') WHERE symbol = 'sz000001';
+func CreateFactorAsofMacros(db *sql.DB) error {
+	if err := CreateTable(db, FactorHistorySchema); err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	query := factorAsofMacroSQL()
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to create or replace macro %s: %w", FactorAsofMacroName, err)
+	}
+
+	query = qfqAsofMacroSQL()
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to create or replace macro %s: %w", QfqAsofMacroName, err)
+	}
+	return nil
+}
+
+// QueryQfqAsof 查询 asof 当日看到的前复权日线，用于复现当时生成的信号
+func QueryQfqAsof(db *sql.DB, symbol string, asof time.Time, startDate *time.Time) ([]model.StockData, error) {
+	query := fmt.Sprintf(
+		"SELECT symbol, open, high, low, close, amount, volume, date FROM %s(DATE '%s') WHERE symbol = ?",
+		QfqAsofMacroName, asof.Format("2006-01-02"),
+	)
+	args := []interface{}{symbol}
+	if startDate != nil {
+		query += " AND date >= ?"
+		args = append(args, *startDate)
+	}
+	query += " ORDER BY date"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query qfq asof: %w", err)
+	}
+	defer rows.Close()
+
+	var results []model.StockData
+	for rows.Next() {
+		var stock model.StockData
+		if err := rows.Scan(
+			&stock.Symbol,
+			&stock.Open,
+			&stock.High,
+			&stock.Low,
+			&stock.Close,
+			&stock.Amount,
+			&stock.Volume,
+			&stock.Date,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan qfq asof data: %w", err)
+		}
+		results = append(results, stock)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return results, nil
+}
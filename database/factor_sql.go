@@ -0,0 +1,219 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+)
+
+// FactorVerifySchema verify-factors 的差异报告
+var FactorVerifySchema = TableSchema{
+	Name: "raw_factor_verify",
+	Columns: []string{
+		"symbol VARCHAR",
+		"date DATE",
+		"field VARCHAR /*pre_close qfq_factor hfq_factor 或 missing_go / missing_sql*/",
+		"go_value DOUBLE",
+		"sql_value DOUBLE",
+		"diff DOUBLE",
+	},
+}
+
+// factorSQL 用窗口函数实现与 tdx.CalculateFqFactor 相同的算法：
+//  1. 交易日与除权除息日合并排序，非交易日及收盘价无效的日期沿用上一个有效收盘价
+//  2. 前收盘价 = ((昨收*10 - 分红) + 配股*配股价) / (10 + 配股 + 送转股)，有扩缩股时再除以扩缩股比例
+//  3. 前复权因子为 (次日前收/当日收盘) 的倒序累乘，后复权因子为 (昨收/当日前收) 的正序累乘
+//
+// filter 为限制 raw_stocks_daily 的 WHERE 条件，为空表示全部股票
+func factorSQL(filter string) string {
+	if filter == "" {
+		filter = "TRUE"
+	}
+	return fmt.Sprintf(`
+		WITH bars AS (
+			SELECT symbol, date, close FROM %[1]s WHERE %[3]s
+		),
+		syms AS (
+			SELECT DISTINCT symbol FROM bars
+		),
+		ev AS (
+			SELECT
+				y.symbol,
+				x.date,
+				MAX(x.fenhong) AS fenhong,
+				MAX(x.peigujia) AS peigujia,
+				MAX(x.songzhuangu) AS songzhuangu,
+				MAX(x.peigu) AS peigu,
+				MAX(x.suogu) AS suogu
+			FROM %[2]s x
+			JOIN syms y ON x.code = SUBSTR(y.symbol, 3)
+			GROUP BY y.symbol, x.date
+		),
+		combined AS (
+			SELECT
+				COALESCE(b.symbol, e.symbol) AS symbol,
+				COALESCE(b.date, e.date) AS date,
+				b.symbol IS NOT NULL AS is_trade,
+				CASE WHEN b.close > 0 THEN b.close END AS valid_close,
+				COALESCE(e.fenhong, 0) AS fenhong,
+				COALESCE(e.peigujia, 0) AS peigujia,
+				COALESCE(e.songzhuangu, 0) AS songzhuangu,
+				COALESCE(e.peigu, 0) AS peigu,
+				COALESCE(e.suogu, 0) AS suogu
+			FROM bars b
+			FULL OUTER JOIN ev e ON b.symbol = e.symbol AND b.date = e.date
+		),
+		filled AS (
+			SELECT
+				*,
+				COALESCE(
+					LAST_VALUE(valid_close IGNORE NULLS) OVER (PARTITION BY symbol ORDER BY date ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW),
+					FIRST_VALUE(valid_close IGNORE NULLS) OVER (PARTITION BY symbol ORDER BY date ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING),
+					0
+				) AS close
+			FROM combined
+		),
+		pre AS (
+			SELECT
+				*,
+				LAG(close) OVER w AS prev_close,
+				CASE
+					WHEN LAG(close) OVER w IS NULL OR LAG(close) OVER w = 0 THEN close
+					WHEN 10 + peigu + songzhuangu = 0 THEN LAG(close) OVER w / CASE WHEN suogu > 0 THEN suogu ELSE 1 END
+					ELSE ((LAG(close) OVER w * 10 - fenhong) + peigu * peigujia) / (10 + peigu + songzhuangu)
+						/ CASE WHEN suogu > 0 THEN suogu ELSE 1 END
+				END AS pre_close
+			FROM filled
+			WINDOW w AS (PARTITION BY symbol ORDER BY date)
+		),
+		ratios AS (
+			SELECT
+				*,
+				CASE
+					WHEN LEAD(pre_close) OVER w IS NULL THEN 1.0
+					WHEN is_trade AND close <> 0 THEN LEAD(pre_close) OVER w / close
+					ELSE 1.0
+				END AS qfq_ratio,
+				CASE
+					WHEN prev_close IS NULL OR pre_close = 0 THEN 1.0
+					ELSE prev_close / pre_close
+				END AS hfq_ratio
+			FROM pre
+			WINDOW w AS (PARTITION BY symbol ORDER BY date)
+		),
+		factors AS (
+			SELECT
+				symbol,
+				date,
+				is_trade,
+				close,
+				pre_close,
+				PRODUCT(qfq_ratio) OVER (PARTITION BY symbol ORDER BY date ROWS BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING) AS qfq_factor,
+				PRODUCT(hfq_ratio) OVER (PARTITION BY symbol ORDER BY date ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS hfq_factor
+			FROM ratios
+		)
+		SELECT
+			symbol,
+			date,
+			ROUND(close, 4) AS close,
+			ROUND(pre_close, 4) AS pre_close,
+			ROUND(qfq_factor, 4) AS qfq_factor,
+			ROUND(hfq_factor, 4) AS hfq_factor
+		FROM factors
+		WHERE is_trade
+	`, StocksSchema.Name, XdxrViewName, filter)
+}
+
+// ComputeFactorsSQL 在数据库内重算 symbols 的复权因子并替换旧数据，无需 CSV 中转
+func ComputeFactorsSQL(db *sql.DB, symbols []string) error {
+	if err := ensureFactorTables(db); err != nil {
+		return err
+	}
+
+	for _, batch := range symbolBatches(symbols) {
+		query := fmt.Sprintf("DELETE FROM %s WHERE symbol IN (%s)", FactorSchema.Name, batch)
+		if _, err := db.Exec(query); err != nil {
+			return fmt.Errorf("failed to delete factors: %w", err)
+		}
+
+		query = fmt.Sprintf(`
+			INSERT INTO %s (symbol, date, close, pre_close, qfq_factor, hfq_factor)
+			%s
+		`, FactorSchema.Name, factorSQL(fmt.Sprintf("symbol IN (%s)", batch)))
+		if _, err := db.Exec(query); err != nil {
+			return fmt.Errorf("failed to compute factors in sql: %w", err)
+		}
+	}
+	return logSymbolsChanged(db, FactorSchema.Name, symbols)
+}
+
+// FactorVerifyResult 两种算法对比结果
+type FactorVerifyResult struct {
+	Rows    int64 // 参与对比的 (symbol, date) 数
+	Diffs   int64 // 超出容差的记录数
+	Symbols int64 // 存在差异的股票数
+}
+
+// VerifyFactors 对比 Go 算法导出的 CSV 与 SQL 算法的结果，超出 tolerance 的差异写入 raw_factor_verify
+func VerifyFactors(db *sql.DB, goCSV string, tolerance float64) (*FactorVerifyResult, error) {
+	if err := DropTable(db, FactorVerifySchema); err != nil {
+		return nil, fmt.Errorf("failed to drop table: %w", err)
+	}
+	if err := CreateTable(db, FactorVerifySchema); err != nil {
+		return nil, fmt.Errorf("failed to create table: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (symbol, date, field, go_value, sql_value, diff)
+		WITH g AS (
+			SELECT * FROM read_csv('%[2]s',
+				header=true,
+				columns={'symbol': 'VARCHAR', 'date': 'DATE', 'close': 'DOUBLE', 'pre_close': 'DOUBLE', 'qfq_factor': 'DOUBLE', 'hfq_factor': 'DOUBLE'},
+				dateformat='%%Y-%%m-%%d')
+		),
+		s AS (%[3]s),
+		j AS (
+			SELECT
+				COALESCE(g.symbol, s.symbol) AS symbol,
+				COALESCE(g.date, s.date) AS date,
+				g.symbol IS NULL AS missing_go,
+				s.symbol IS NULL AS missing_sql,
+				g.pre_close AS g_pre, s.pre_close AS s_pre,
+				g.qfq_factor AS g_qfq, s.qfq_factor AS s_qfq,
+				g.hfq_factor AS g_hfq, s.hfq_factor AS s_hfq
+			FROM g
+			FULL OUTER JOIN s ON g.symbol = s.symbol AND g.date = s.date
+		),
+		cmp AS (
+			SELECT symbol, date, 'missing_go' AS field, NULL AS go_value, s_pre AS sql_value, NULL AS diff FROM j WHERE missing_go
+			UNION ALL
+			SELECT symbol, date, 'missing_sql', g_pre, NULL, NULL FROM j WHERE missing_sql
+			UNION ALL
+			SELECT symbol, date, 'pre_close', g_pre, s_pre, ABS(g_pre - s_pre) FROM j WHERE NOT missing_go AND NOT missing_sql
+			UNION ALL
+			SELECT symbol, date, 'qfq_factor', g_qfq, s_qfq, ABS(g_qfq - s_qfq) FROM j WHERE NOT missing_go AND NOT missing_sql
+			UNION ALL
+			SELECT symbol, date, 'hfq_factor', g_hfq, s_hfq, ABS(g_hfq - s_hfq) FROM j WHERE NOT missing_go AND NOT missing_sql
+		)
+		SELECT * FROM cmp
+		WHERE diff IS NULL OR diff > %[4]g
+		ORDER BY symbol, date, field
+	`, FactorVerifySchema.Name, goCSV, factorSQL(""), tolerance)
+
+	if _, err := db.Exec(query); err != nil {
+		return nil, fmt.Errorf("failed to verify factors: %w", err)
+	}
+
+	res := &FactorVerifyResult{}
+	query = fmt.Sprintf("SELECT COUNT(*), COUNT(DISTINCT symbol) FROM %s", FactorVerifySchema.Name)
+	if err := db.QueryRow(query).Scan(&res.Diffs, &res.Symbols); err != nil {
+		return nil, fmt.Errorf("failed to summarize factor verify: %w", err)
+	}
+	query = fmt.Sprintf("SELECT COUNT(*) FROM (%s)", factorSQL(""))
+	if err := db.QueryRow(query).Scan(&res.Rows); err != nil {
+		return nil, fmt.Errorf("failed to count sql factors: %w", err)
+	}
+
+	return res, nil
+}
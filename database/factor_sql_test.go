@@ -0,0 +1,125 @@
+package database
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jing2uo/tdx2db/model"
+	"github.com/jing2uo/tdx2db/tdx"
+)
+
+// TestComputeFactorsSQLMatchesGo 同一份日线和股本变迁分别用 SQL 和 Go 计算复权因子，
+// 每只股票对应一种事件：派现、送转、配股、扩缩股、非流通股缩股、周末除息和无事件
+func TestComputeFactorsSQLMatchesGo(t *testing.T) {
+	db, err := Connect(model.DBConfig{Path: filepath.Join(t.TempDir(), "tdx.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, s := range []TableSchema{StocksSchema, GBBQSchema} {
+		if err := CreateTable(db, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := CreateXdxrView(db); err != nil {
+		t.Fatal(err)
+	}
+
+	codes := []string{"000001", "000002", "000003", "000004", "000005", "000006", "000007"}
+	closes := []struct {
+		date  string
+		close float64
+	}{{"2024-01-05", 10}, {"2024-01-08", 9.8}, {"2024-01-09", 9.9}}
+	var symbols []string
+	stocks := map[string][]model.StockData{}
+	for _, code := range codes {
+		symbol := "sz" + code
+		symbols = append(symbols, symbol)
+		for _, c := range closes {
+			date, _ := time.Parse("2006-01-02", c.date)
+			stocks[symbol] = append(stocks[symbol], model.StockData{Symbol: symbol, Close: c.close, Date: date})
+			if _, err := db.Exec("INSERT INTO raw_stocks_daily (symbol, close, date) VALUES (?, ?, ?)", symbol, c.close, date); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if _, err := db.Exec(`INSERT INTO raw_gbbq (category, date, code, c1, c2, c3, c4) VALUES
+		(1, '2024-01-08', '000001', 5, 0, 0, 0),
+		(1, '2024-01-08', '000002', 0, 0, 10, 0),
+		(1, '2024-01-08', '000003', 0, 4, 0, 3),
+		(11, '2024-01-08', '000004', 0, 0, 0.1, 0),
+		(1, '2024-01-06', '000005', 5, 0, 0, 0),
+		(12, '2024-01-08', '000006', 0, 0, 0.5, 0),
+		(5, '2024-01-08', '000007', 100, 200, 100, 300)`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ComputeFactorsSQL(db, symbols); err != nil {
+		t.Fatal(err)
+	}
+	sqlFactors := map[string]model.Factor{}
+	rows, err := db.Query("SELECT symbol, date, close, pre_close, qfq_factor, hfq_factor FROM raw_adjust_factor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var f model.Factor
+		if err := rows.Scan(&f.Symbol, &f.Date, &f.Close, &f.PreClose, &f.QfqFactor, &f.HfqFactor); err != nil {
+			t.Fatal(err)
+		}
+		sqlFactors[f.Symbol+f.Date.Format("2006-01-02")] = f
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	xdxr, err := QueryAllXdxr(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byCode := map[string][]model.XdxrData{}
+	for _, x := range xdxr {
+		byCode[x.Code] = append(byCode[x.Code], x)
+	}
+
+	count := 0
+	for _, symbol := range symbols {
+		goFactors, err := tdx.CalculateFqFactor(stocks[symbol], byCode[symbol[2:]])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, g := range goFactors {
+			key := g.Symbol + g.Date.Format("2006-01-02")
+			s, ok := sqlFactors[key]
+			if !ok {
+				t.Errorf("%s: missing in sql", key)
+				continue
+			}
+			count++
+			// SQL 结果保留 4 位小数
+			for _, v := range []struct {
+				field             string
+				goValue, sqlValue float64
+			}{
+				{"pre_close", g.PreClose, s.PreClose},
+				{"qfq_factor", g.QfqFactor, s.QfqFactor},
+				{"hfq_factor", g.HfqFactor, s.HfqFactor},
+			} {
+				if math.Abs(v.goValue-v.sqlValue) > 1e-4 {
+					t.Errorf("%s %s: go %v, sql %v", key, v.field, v.goValue, v.sqlValue)
+				}
+			}
+		}
+	}
+	if count != len(sqlFactors) || count != len(symbols)*len(closes) {
+		t.Fatalf("compared %d rows, sql has %d, want %d", count, len(sqlFactors), len(symbols)*len(closes))
+	}
+	// 两种引擎都算错时上面的对比发现不了，抽查派现的前复权因子
+	if f := sqlFactors["sz0000012024-01-05"]; math.Abs(f.QfqFactor-0.95) > 1e-4 {
+		t.Errorf("sz000001 first qfq = %v, want 0.95", f.QfqFactor)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	_ "github.com/duckdb/duckdb-go/v2"
 	"github.com/jing2uo/tdx2db/model"
+	"github.com/jing2uo/tdx2db/tdx"
 )
 
 var GBBQSchema = TableSchema{
@@ -24,19 +25,25 @@ var GBBQSchema = TableSchema{
 var XdxrViewName = "v_xdxr"
 var TurnoverViewName = "v_turnover"
 
-func CreateXdxrView(db *sql.DB) error {
-	query := fmt.Sprintf(`
+func xdxrViewSQL() string {
+	return fmt.Sprintf(`
 	CREATE OR REPLACE VIEW %s AS
 	SELECT
 		date,
 		code,
-		c1 as fenhong,
-		c2 as peigujia,
-		c3 as songzhuangu,
-		c4 as peigu
+		COALESCE(MAX(c1) FILTER (WHERE category = 1), 0) as fenhong,
+		COALESCE(MAX(c2) FILTER (WHERE category = 1), 0) as peigujia,
+		COALESCE(MAX(c3) FILTER (WHERE category = 1), 0) as songzhuangu,
+		COALESCE(MAX(c4) FILTER (WHERE category = 1), 0) as peigu,
+		COALESCE(PRODUCT(c3) FILTER (WHERE category IN (%s) AND c3 > 0), 1) as suogu
 	FROM %s
-	WHERE category=1;
-	`, XdxrViewName, GBBQSchema.Name)
+	WHERE category IN (%s)
+	GROUP BY date, code;
+	`, XdxrViewName, categoryIn(tdx.ScaleCategories), GBBQSchema.Name, categoryIn(tdx.XdxrCategories))
+}
+
+func CreateXdxrView(db *sql.DB) error {
+	query := xdxrViewSQL()
 
 	_, err := db.Exec(query)
 	if err != nil {
@@ -45,43 +52,25 @@ func CreateXdxrView(db *sql.DB) error {
 	return nil
 }
 
-func CreateTurnoverView(db *sql.DB) error {
-	query := fmt.Sprintf(`
+func turnoverViewSQL() string {
+	return fmt.Sprintf(`
     CREATE OR REPLACE VIEW %s AS
-    WITH base_cc AS (
-        SELECT
-            date,
-            code,
-            c3 AS float_shares,
-            c4 AS total_shares
-        FROM %s
-        WHERE category IN (2, 3, 5, 7, 8, 9, 10)
-    ),
-    expanded AS (
-        SELECT
-            d.date,
-            d.symbol,
-            LAST_VALUE(base_cc.float_shares IGNORE NULLS)
-                OVER (PARTITION BY d.symbol ORDER BY d.date) AS float_shares,
-            LAST_VALUE(base_cc.total_shares IGNORE NULLS)
-                OVER (PARTITION BY d.symbol ORDER BY d.date) AS total_shares
-        FROM %s d
-        LEFT JOIN base_cc
-            ON base_cc.code = SUBSTR(d.symbol, 3)
-            AND base_cc.date = d.date
-    )
-
     SELECT
         r.date,
         r.symbol,
-        ROUND(r.volume / (e.float_shares * 10000), 4) AS turnover,
-        ROUND(e.float_shares * 10000 * r.close, 4) AS circ_mv,
-        ROUND(e.total_shares * 10000 * r.close, 4) AS total_mv
+        ROUND(r.volume / (c.float_shares * 10000), 4) AS turnover,
+        ROUND(c.float_shares * 10000 * r.close, 4) AS circ_mv,
+        ROUND(c.total_shares * 10000 * r.close, 4) AS total_mv
     FROM %s r
-    JOIN expanded e
-        ON r.symbol = e.symbol
-        AND r.date = e.date;
-	`, TurnoverViewName, GBBQSchema.Name, StocksSchema.Name, StocksSchema.Name)
+    LEFT JOIN %s c
+        ON r.symbol = c.symbol
+        AND r.date = c.date;
+	`, TurnoverViewName, StocksSchema.Name, CapitalSchema.Name)
+}
+
+// CreateTurnoverView 换手率和市值，股本取自 raw_capital，需先调用 BuildCapital
+func CreateTurnoverView(db *sql.DB) error {
+	query := turnoverViewSQL()
 
 	_, err := db.Exec(query)
 	if err != nil {
@@ -109,7 +98,7 @@ func ImportGbbqCsv(db *sql.DB, csvPath string) error {
 }
 
 func QueryAllXdxr(db *sql.DB) ([]model.XdxrData, error) {
-	query := fmt.Sprintf("SELECT * FROM %s ORDER BY code, date", XdxrViewName)
+	query := fmt.Sprintf("SELECT date, code, fenhong, peigujia, songzhuangu, peigu, suogu FROM %s ORDER BY code, date", XdxrViewName)
 
 	rows, err := db.Query(query)
 	if err != nil {
@@ -120,7 +109,7 @@ func QueryAllXdxr(db *sql.DB) ([]model.XdxrData, error) {
 	var results []model.XdxrData
 	for rows.Next() {
 		var xdxr model.XdxrData
-		err := rows.Scan(&xdxr.Date, &xdxr.Code, &xdxr.Fenhong, &xdxr.Peigujia, &xdxr.Songzhuangu, &xdxr.Peigu)
+		err := rows.Scan(&xdxr.Date, &xdxr.Code, &xdxr.Fenhong, &xdxr.Peigujia, &xdxr.Songzhuangu, &xdxr.Peigu, &xdxr.Suogu)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan xdxr data: %w", err)
 		}
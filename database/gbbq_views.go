@@ -0,0 +1,172 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jing2uo/tdx2db/model"
+	"github.com/jing2uo/tdx2db/tdx"
+)
+
+// 股本变迁按事件类别拆分的视图，c1..c4 的含义见 tdx.CategoryDetail
+var gbbqViews = []ColumnViews{
+	{
+		name: "v_gbbq_dividend",
+		from: "raw_gbbq WHERE category = 1",
+		desc: "1 除权除息：派现、送转、配股",
+		fields: []ColumnView{
+			{name: "date"},
+			{name: "code"},
+			{name: "c1", alias: "cash_per_10", desc: "每10股派现(元)"},
+			{name: "c3", alias: "bonus_per_10", desc: "每10股送转股(股)"},
+			{name: "c4", alias: "rights_per_10", desc: "每10股配股(股)"},
+			{name: "c2", alias: "rights_price", desc: "配股价(元)"},
+		},
+	},
+	{
+		name: "v_gbbq_share_change",
+		from: "raw_gbbq WHERE category IN (" + categoryIn(tdx.ShareChangeCategories) + ")",
+		desc: "股本变动：送配股上市、非流通股上市、股本变化、回购、增发上市、转配股上市、可转债上市",
+		fields: []ColumnView{
+			{name: "date"},
+			{name: "code"},
+			{name: "category"},
+			{name: gbbqEventExpr(), alias: "event", desc: "事件名称"},
+			{name: "c1", alias: "prev_float_shares", desc: "前流通盘(万股)"},
+			{name: "c2", alias: "prev_total_shares", desc: "前总股本(万股)"},
+			{name: "c3", alias: "float_shares", desc: "后流通盘(万股)"},
+			{name: "c4", alias: "total_shares", desc: "后总股本(万股)"},
+			{name: "c3 - c1", alias: "float_change", desc: "流通盘变动(万股)"},
+			{name: "c4 - c2", alias: "total_change", desc: "总股本变动(万股)"},
+		},
+	},
+	{
+		name: "v_gbbq_issuance",
+		from: `(
+			SELECT date, code, 'rights' AS kind, c2 AS price, c4 AS ratio_per_10, NULL::DOUBLE AS shares
+			FROM raw_gbbq WHERE category = 1 AND c4 > 0
+			UNION ALL
+			SELECT date, code, 'seo' AS kind, c2 AS price, NULL::DOUBLE AS ratio_per_10, c3 AS shares
+			FROM raw_gbbq WHERE category = 6
+		)`,
+		desc: "配股 (1 除权除息中的配股部分) 和 6 增发新股",
+		fields: []ColumnView{
+			{name: "date"},
+			{name: "code"},
+			{name: "kind", desc: "rights 配股 / seo 增发"},
+			{name: "price", desc: "配股价或增发价(元)"},
+			{name: "ratio_per_10", desc: "每10股配股(股)，仅配股"},
+			{name: "shares", desc: "增发数量(万股)，仅增发"},
+		},
+	},
+	{
+		name: "v_gbbq_buyback",
+		from: "raw_gbbq WHERE category = 7",
+		desc: "7 股份回购",
+		fields: []ColumnView{
+			{name: "date"},
+			{name: "code"},
+			{name: "c1", alias: "prev_float_shares", desc: "前流通盘(万股)"},
+			{name: "c2", alias: "prev_total_shares", desc: "前总股本(万股)"},
+			{name: "c3", alias: "float_shares", desc: "后流通盘(万股)"},
+			{name: "c4", alias: "total_shares", desc: "后总股本(万股)"},
+			{name: "c2 - c4", alias: "buyback_shares", desc: "注销股数(万股)"},
+		},
+	},
+	{
+		name: "v_gbbq_scale",
+		from: "raw_gbbq WHERE category IN (" + categoryIn(tdx.ScaleCategories) + ")",
+		desc: "11 扩缩股 12 非流通股缩股",
+		fields: []ColumnView{
+			{name: "date"},
+			{name: "code"},
+			{name: "category"},
+			{name: gbbqEventExpr(), alias: "event", desc: "事件名称"},
+			{name: "c3", alias: "ratio", desc: "比例"},
+		},
+	},
+	{
+		name: "v_gbbq_warrant",
+		from: "raw_gbbq WHERE category IN (13, 14)",
+		desc: "13 送认购权证 14 送认沽权证",
+		fields: []ColumnView{
+			{name: "date"},
+			{name: "code"},
+			{name: "CASE category WHEN 13 THEN 'call' ELSE 'put' END", alias: "warrant_type", desc: "call 认购 / put 认沽"},
+			{name: "c1", alias: "strike_price", desc: "行权价(元)"},
+			{name: "c3", alias: "units", desc: "份数"},
+		},
+	},
+}
+
+// categoryIn 把类别列表拼成 IN (...) 中的内容
+func categoryIn(categories []int) string {
+	in := make([]string, len(categories))
+	for i, c := range categories {
+		in[i] = strconv.Itoa(c)
+	}
+	return strings.Join(in, ", ")
+}
+
+// gbbqEventExpr 由 tdx.Category 生成类别名称的 CASE 表达式
+func gbbqEventExpr() string {
+	keys := make([]int, 0, len(tdx.Category))
+	for k := range tdx.Category {
+		n, err := strconv.Atoi(k)
+		if err == nil {
+			keys = append(keys, n)
+		}
+	}
+	sort.Ints(keys)
+
+	var sb strings.Builder
+	sb.WriteString("CASE category")
+	for _, k := range keys {
+		fmt.Fprintf(&sb, " WHEN %d THEN '%s'", k, tdx.Category[strconv.Itoa(k)])
+	}
+	sb.WriteString(" END")
+	return sb.String()
+}
+
+// CreateGbbqViews 创建按事件类别拆分的股本变迁视图
+func CreateGbbqViews(db *sql.DB) error {
+	for _, view := range gbbqViews {
+		if err := createView(db, view); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QueryGbbqEvents 读取 raw_gbbq 并按类别解码，可按类别过滤，不传类别时返回全部
+func QueryGbbqEvents(db *sql.DB, categories ...int) ([]tdx.GbbqEvent, error) {
+	query := fmt.Sprintf("SELECT category, code, date, c1, c2, c3, c4 FROM %s", GBBQSchema.Name)
+	if len(categories) > 0 {
+		query += fmt.Sprintf(" WHERE category IN (%s)", categoryIn(categories))
+	}
+	query += " ORDER BY code, date, category"
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query gbbq events: %w", err)
+	}
+	defer rows.Close()
+
+	var results []tdx.GbbqEvent
+	for rows.Next() {
+		var g model.GbbqData
+		if err := rows.Scan(&g.Category, &g.Code, &g.Date, &g.C1, &g.C2, &g.C3, &g.C4); err != nil {
+			return nil, fmt.Errorf("failed to scan gbbq data: %w", err)
+		}
+		results = append(results, tdx.DecodeGbbqEvent(g))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating gbbq data: %w", err)
+	}
+
+	return results, nil
+}
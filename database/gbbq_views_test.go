@@ -0,0 +1,53 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jing2uo/tdx2db/model"
+	"github.com/jing2uo/tdx2db/tdx"
+)
+
+func TestQueryGbbqEvents(t *testing.T) {
+	db, err := Connect(model.DBConfig{Path: filepath.Join(t.TempDir(), "tdx.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := CreateTable(db, GBBQSchema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO raw_gbbq (category, date, code, c1, c2, c3, c4) VALUES
+		(1, '2024-05-06', '600000', 3.5, 0, 2, 0),
+		(5, '2024-05-07', '600000', 100, 200, 110, 220),
+		(11, '2024-05-08', '000001', 0, 0, 0.5, 0)`); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := QueryGbbqEvents(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("got %d events, want 3", len(all))
+	}
+	// 按 code, date 排序
+	if e, ok := all[0].(tdx.ScaleEvent); !ok || e.Code != "000001" || e.Ratio != 0.5 {
+		t.Errorf("events[0] = %#v", all[0])
+	}
+	if e, ok := all[1].(tdx.DividendEvent); !ok || e.CashPer10 != 3.5 || e.BonusPer10 != 2 {
+		t.Errorf("events[1] = %#v", all[1])
+	}
+
+	changes, err := QueryGbbqEvents(db, tdx.ShareChangeCategories...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("got %d share changes, want 1", len(changes))
+	}
+	if e, ok := changes[0].(tdx.ShareChangeEvent); !ok || e.Float != 110 || e.Total != 220 || !e.EventDate().Equal(time.Date(2024, 5, 7, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("share change = %#v", changes[0])
+	}
+}
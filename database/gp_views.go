@@ -3,7 +3,6 @@ package database
 import (
 	"database/sql"
 	"fmt"
-	"strings"
 )
 
 var gpViews = []ColumnViews{
@@ -145,31 +144,8 @@ func CreateGpViews(db *sql.DB) error {
 }
 
 func createView(db *sql.DB, view ColumnViews) error {
-	columns := make([]string, 0, len(view.fields))
-	for _, field := range view.fields {
-		columns = append(columns, formatColumn(field))
-	}
-
-	query := fmt.Sprintf(`
-		CREATE OR REPLACE VIEW %s AS
-		SELECT %s
-		FROM %s;
-	`, view.name, strings.Join(columns, ",\n\t\t"), view.from)
-
-	if _, err := db.Exec(query); err != nil {
+	if _, err := db.Exec(viewDDL(view)); err != nil {
 		return fmt.Errorf("failed to create view %s: %w", view.name, err)
 	}
 	return nil
 }
-
-func formatColumn(field ColumnView) string {
-	expr := field.name
-	if field.alias != "" {
-		expr = fmt.Sprintf("%s AS %s", field.name, field.alias)
-	}
-	if field.desc != "" {
-		clean := strings.ReplaceAll(field.desc, "*/", "")
-		expr = fmt.Sprintf("%s /* %s */", expr, clean)
-	}
-	return expr
-}
@@ -11,86 +11,360 @@ import (
 	"github.com/jing2uo/tdx2db/tdx"
 )
 
-func ImportStockDayFiles(db *sql.DB, dayFileDir string, validPrefixes []string, drop bool, latestDate map[string]time.Time) error {
+// DayMergeStats 日线合并结果
+type DayMergeStats struct {
+	Inserted  int64 // 新增的日线
+	Updated   int64 // 已存在但数据有修订的日线
+	Unchanged int64 // 与库中一致的日线
+}
+
+// ImportStockDayFiles 导入 .day 文件。drop 为 true 时重建表后直接追加，
+// 否则先写入暂存表再合并：新日线插入、有修订的日线覆盖，重复导入是安全的
+func ImportStockDayFiles(db *sql.DB, dayFileDir string, validPrefixes []string, drop bool) (*DayMergeStats, error) {
+	return importStockDay(db, drop, func(handle func(tdx.DayKlineRecord) error) error {
+		return tdx.StreamDayFiles(dayFileDir, validPrefixes, handle)
+	})
+}
+
+// ImportStockDayZip 直接从四代行情压缩包 (g4day) 导入日线，无需先转档为 .day 文件。
+func ImportStockDayZip(db *sql.DB, zipPath string, validPrefixes []string) (*DayMergeStats, error) {
+	return importStockDay(db, false, func(handle func(tdx.DayKlineRecord) error) error {
+		return tdx.StreamDayZip(zipPath, validPrefixes, handle)
+	})
+}
+
+func importStockDay(db *sql.DB, drop bool, stream func(func(tdx.DayKlineRecord) error) error) (*DayMergeStats, error) {
 	if drop {
 		if err := DropTable(db, StocksSchema); err != nil {
-			return fmt.Errorf("failed to drop table: %w", err)
+			return nil, fmt.Errorf("failed to drop table: %w", err)
 		}
 	}
 	if err := CreateTable(db, StocksSchema); err != nil {
-		return fmt.Errorf("failed to create table: %w", err)
+		return nil, fmt.Errorf("failed to create table: %w", err)
+	}
+
+	// 全新的表直接追加，无需合并
+	target := StocksSchema
+	stage := TableSchema{Name: StocksSchema.Name + "_stage", Columns: StocksSchema.Columns}
+	if !drop {
+		target = stage
+		if err := DropTable(db, stage); err != nil {
+			return nil, fmt.Errorf("drop stage: %w", err)
+		}
+		if err := CreateTable(db, stage); err != nil {
+			return nil, fmt.Errorf("create stage: %w", err)
+		}
+		defer DropTable(db, stage)
 	}
 
 	ctx := context.Background()
+	appended, err := NewDuckDBBackend(db).Append(ctx, target, func(emit func(row ...any) error) error {
+		return stream(func(record tdx.DayKlineRecord) error {
+			return emit(record.Symbol, record.Open, record.High, record.Low, record.Close, record.Amount, record.Volume, record.Date)
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("stream day records: %w", err)
+	}
+
+	if drop {
+		if err := logTableChanged(db, StocksSchema.Name); err != nil {
+			return nil, err
+		}
+		return &DayMergeStats{Inserted: appended}, nil
+	}
+	return mergeStockDay(ctx, db, stage.Name)
+}
+
+// mergeStockDay 把暂存表合并进 raw_stocks_daily。有修订的股票同时删除其复权因子状态，
+// 下次更新因子时全量重算；修订和补录（早于该股票最新日期）的区间记入变更日志
+func mergeStockDay(ctx context.Context, db *sql.DB, stageName string) (*DayMergeStats, error) {
 	conn, err := db.Conn(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get database conn: %w", err)
+		return nil, fmt.Errorf("failed to get database conn: %w", err)
 	}
 	defer conn.Close()
 
-	if err := conn.Raw(func(dc any) error {
-		driverConn, ok := dc.(driver.Conn)
-		if !ok {
-			return fmt.Errorf("unexpected driver conn type %T", dc)
-		}
+	diffName := StocksSchema.Name + "_diff"
+	defer execTableDDL(ctx, conn, fmt.Sprintf("DROP TABLE IF EXISTS %s", diffName))
 
-		appender, err := duckdb.NewAppenderFromConn(driverConn, "", StocksSchema.Name)
-		if err != nil {
-			return fmt.Errorf("new appender: %w", err)
+	// 同一 symbol、date 在源数据中重复时只取一条
+	query := fmt.Sprintf(`
+		CREATE OR REPLACE TABLE %[1]s AS
+		WITH s AS (
+			SELECT * FROM %[2]s
+			QUALIFY ROW_NUMBER() OVER (PARTITION BY symbol, date) = 1
+		)
+		SELECT s.*, t.symbol IS NULL AS is_new
+		FROM s
+		LEFT JOIN %[3]s t ON t.symbol = s.symbol AND t.date = s.date
+		WHERE t.symbol IS NULL
+		   OR t.open IS DISTINCT FROM s.open
+		   OR t.high IS DISTINCT FROM s.high
+		   OR t.low IS DISTINCT FROM s.low
+		   OR t.close IS DISTINCT FROM s.close
+		   OR t.amount IS DISTINCT FROM s.amount
+		   OR t.volume IS DISTINCT FROM s.volume
+	`, diffName, stageName, StocksSchema.Name)
+	if err := execTableDDL(ctx, conn, query); err != nil {
+		return nil, fmt.Errorf("diff stage: %w", err)
+	}
+
+	stats := &DayMergeStats{}
+	var staged int64
+	query = fmt.Sprintf(`
+		SELECT
+			(SELECT COUNT(*) FROM (SELECT DISTINCT symbol, date FROM %[1]s)),
+			COUNT(*) FILTER (WHERE is_new),
+			COUNT(*) FILTER (WHERE NOT is_new)
+		FROM %[2]s
+	`, stageName, diffName)
+	if err := conn.QueryRowContext(ctx, query).Scan(&staged, &stats.Inserted, &stats.Updated); err != nil {
+		return nil, fmt.Errorf("count merge rows: %w", err)
+	}
+	stats.Unchanged = staged - stats.Inserted - stats.Updated
+
+	stateCols, err := tableColumns(db, FactorStateSchema.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := CreateTable(db, ChangeLogSchema); err != nil {
+		return nil, err
+	}
+
+	if err := execTableDDL(ctx, conn, "BEGIN"); err != nil {
+		return nil, fmt.Errorf("begin merge: %w", err)
+	}
+	ranges := fmt.Sprintf(`
+		SELECT d.symbol, MIN(d.date) AS from_time, MAX(d.date) AS to_time
+		FROM %[1]s d
+		LEFT JOIN (SELECT symbol, MAX(date) AS last_date FROM %[2]s GROUP BY symbol) w ON w.symbol = d.symbol
+		WHERE NOT d.is_new OR d.date <= w.last_date
+		GROUP BY d.symbol
+	`, diffName, StocksSchema.Name)
+	if err := logChanges(ctx, conn, StocksSchema.Name, ranges); err != nil {
+		_ = execTableDDL(ctx, conn, "ROLLBACK")
+		return nil, err
+	}
+	query = fmt.Sprintf(`
+		INSERT OR REPLACE INTO %[1]s (symbol, open, high, low, close, amount, volume, date)
+		SELECT symbol, open, high, low, close, amount, volume, date FROM %[2]s
+	`, StocksSchema.Name, diffName)
+	if err := execTableDDL(ctx, conn, query); err != nil {
+		_ = execTableDDL(ctx, conn, "ROLLBACK")
+		return nil, fmt.Errorf("merge rows: %w", err)
+	}
+	if len(stateCols) > 0 && stats.Updated > 0 {
+		query = fmt.Sprintf(
+			"DELETE FROM %s WHERE symbol IN (SELECT DISTINCT symbol FROM %s WHERE NOT is_new)",
+			FactorStateSchema.Name, diffName,
+		)
+		if err := execTableDDL(ctx, conn, query); err != nil {
+			_ = execTableDDL(ctx, conn, "ROLLBACK")
+			return nil, fmt.Errorf("reset factor state: %w", err)
 		}
-		closed := false
-		defer func() {
-			if closed {
-				return
-			}
-			_ = appender.Close()
-		}()
+	}
+	if err := execTableDDL(ctx, conn, "COMMIT"); err != nil {
+		return nil, fmt.Errorf("commit merge: %w", err)
+	}
+
+	return stats, nil
+}
+
+// Import1MinLineFiles 追加 1 分钟数据，只导入晚于每只股票已有最新时间的 K 线，返回新增条数
+func Import1MinLineFiles(db *sql.DB, fileDir string, validPrefixes []string) (int64, error) {
+	return importMinLineFiles(db, OneMinLineSchema, fileDir, validPrefixes, []string{".01", ".lc1"})
+}
+
+// Import5MinLineFiles 追加 5 分钟数据，规则同 Import1MinLineFiles
+func Import5MinLineFiles(db *sql.DB, fileDir string, validPrefixes []string) (int64, error) {
+	return importMinLineFiles(db, FiveMinLineSchema, fileDir, validPrefixes, []string{".5", ".lc5"})
+}
+
+// importMinLineFiles 先把晚于水位的 K 线写入暂存表，再按 (symbol, datetime) 去重后插入，
+// 同一股票同时有 .01 和 .lc1 等多个文件时不会产生重复记录
+func importMinLineFiles(db *sql.DB, schema TableSchema, fileDir string, validPrefixes []string, suffixes []string) (int64, error) {
+	if err := CreateTable(db, schema); err != nil {
+		return 0, fmt.Errorf("failed to create table: %w", err)
+	}
 
-		rowValues := make([]driver.Value, 8)
-		if err := tdx.StreamDayFiles(dayFileDir, validPrefixes, func(record tdx.DayKlineRecord) error {
-			if record.Date.After(latestDate[record.Symbol]) {
-				fmt.Printf("symbol:%s date:%v rdate:%v\n", record.Symbol, latestDate[record.Symbol], record.Date)
-				rowValues[0] = record.Symbol
-				rowValues[1] = record.Open
-				rowValues[2] = record.High
-				rowValues[3] = record.Low
-				rowValues[4] = record.Close
-				rowValues[5] = record.Amount
-				rowValues[6] = record.Volume
-				rowValues[7] = record.Date
-				return appender.AppendRow(rowValues...)
+	watermark, err := minLineWatermarks(db, schema)
+	if err != nil {
+		return 0, err
+	}
+
+	stage := TableSchema{Name: schema.Name + "_stage", Columns: schema.Columns}
+	if err := DropTable(db, stage); err != nil {
+		return 0, fmt.Errorf("drop stage: %w", err)
+	}
+	if err := CreateTable(db, stage); err != nil {
+		return 0, fmt.Errorf("create stage: %w", err)
+	}
+	defer DropTable(db, stage)
+
+	_, err = NewDuckDBBackend(db).Append(context.Background(), stage, func(emit func(row ...any) error) error {
+		return tdx.StreamMinFiles(fileDir, validPrefixes, suffixes, func(record tdx.MinKlineRecord) error {
+			if !record.Datetime.After(watermark[record.Symbol]) {
+				return nil
 			}
-			return nil
-		}); err != nil {
-			return fmt.Errorf("stream day files: %w", err)
-		}
+			return emit(record.Symbol, record.Open, record.High, record.Low, record.Close, record.Amount, record.Volume, record.Datetime)
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
 
-		if err := appender.Close(); err != nil {
-			return fmt.Errorf("close appender: %w", err)
-		}
-		closed = true
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (symbol, open, high, low, close, amount, volume, datetime)
+		SELECT symbol, open, high, low, close, amount, volume, datetime FROM %[2]s
+		QUALIFY ROW_NUMBER() OVER (PARTITION BY symbol, datetime) = 1
+	`, schema.Name, stage.Name)
+	res, err := db.Exec(query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert into %s: %w", schema.Name, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inserted rows of %s: %w", schema.Name, err)
+	}
+	return n, nil
+}
+
+// dedupeMinLine 删除分时表中 (symbol, datetime) 重复的 K 线，没有重复时不改写表
+func dedupeMinLine(db *sql.DB, schema TableSchema) error {
+	cols, err := tableColumns(db, schema.Name)
+	if err != nil {
+		return err
+	}
+	if len(cols) == 0 {
 		return nil
-	}); err != nil {
-		return fmt.Errorf("append rows: %w", err)
 	}
 
+	var dup int64
+	query := fmt.Sprintf(`
+		SELECT COUNT(*) FROM (
+			SELECT 1 FROM %s GROUP BY symbol, datetime HAVING COUNT(*) > 1
+		)
+	`, schema.Name)
+	if err := db.QueryRow(query).Scan(&dup); err != nil {
+		return fmt.Errorf("failed to count duplicates in %s: %w", schema.Name, err)
+	}
+	if dup == 0 {
+		return nil
+	}
+
+	if err := CreateTable(db, ChangeLogSchema); err != nil {
+		return err
+	}
+	ranges := fmt.Sprintf(`
+		SELECT symbol, MIN(datetime) AS from_time, MAX(datetime) AS to_time
+		FROM (SELECT symbol, datetime FROM %s GROUP BY symbol, datetime HAVING COUNT(*) > 1)
+		GROUP BY symbol
+	`, schema.Name)
+	if err := logChanges(context.Background(), db, schema.Name, ranges); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`
+		CREATE OR REPLACE TABLE %[1]s AS
+		SELECT * FROM %[1]s
+		QUALIFY ROW_NUMBER() OVER (PARTITION BY symbol, datetime) = 1
+	`, schema.Name)
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to dedupe %s: %w", schema.Name, err)
+	}
+	fmt.Printf("🧹 %s 删除重复 K 线，涉及 %d 个时间点\n", schema.Name, dup)
 	return nil
 }
 
-func Import1MinLineFiles(db *sql.DB, fileDir string, validPrefixes []string) error {
-	return importMinLineFiles(db, OneMinLineSchema, fileDir, validPrefixes, ".01")
+// minLineWatermarks 每只股票分时表中已有的最新时间
+func minLineWatermarks(db *sql.DB, schema TableSchema) (map[string]time.Time, error) {
+	query := fmt.Sprintf("SELECT symbol, MAX(datetime) FROM %s GROUP BY symbol", schema.Name)
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest datetime of %s: %w", schema.Name, err)
+	}
+	defer rows.Close()
+
+	res := make(map[string]time.Time, 4096)
+	for rows.Next() {
+		var symbol string
+		var latest time.Time
+		if err := rows.Scan(&symbol, &latest); err != nil {
+			return nil, fmt.Errorf("failed to scan latest datetime of %s: %w", schema.Name, err)
+		}
+		res[symbol] = latest
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating latest datetime of %s: %w", schema.Name, err)
+	}
+
+	return res, nil
 }
 
-func Import5MinLineFiles(db *sql.DB, fileDir string, validPrefixes []string) error {
-	return importMinLineFiles(db, FiveMinLineSchema, fileDir, validPrefixes, ".5")
+// PruneMinLine 删除分时表中早于 before 的 K 线，表不存在时不做处理，返回删除条数
+func PruneMinLine(db *sql.DB, schema TableSchema, before time.Time) (int64, error) {
+	cols, err := tableColumns(db, schema.Name)
+	if err != nil {
+		return 0, err
+	}
+	if len(cols) == 0 {
+		return 0, nil
+	}
+
+	if err := CreateTable(db, ChangeLogSchema); err != nil {
+		return 0, err
+	}
+	ranges := fmt.Sprintf(`
+		SELECT NULL AS symbol, MIN(datetime) AS from_time, MAX(datetime) AS to_time
+		FROM %s WHERE datetime < ?
+		HAVING COUNT(*) > 0
+	`, schema.Name)
+	if err := logChanges(context.Background(), db, schema.Name, ranges, before); err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE datetime < ?", schema.Name)
+	res, err := db.Exec(query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune %s: %w", schema.Name, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get pruned rows of %s: %w", schema.Name, err)
+	}
+	return n, nil
 }
 
-func importMinLineFiles(db *sql.DB, schema TableSchema, fileDir string, validPrefixes []string, suffix string) error {
-	if err := DropTable(db, schema); err != nil {
-		return fmt.Errorf("failed to drop table: %w", err)
+// ImportTickZip 解码四代分笔压缩包，写入分笔表并聚合为 1/5 分钟 K 线追加到分时表。
+// 导入前会删除三张表中该交易日的已有数据，重复导入同一天不会产生重复记录。
+func ImportTickZip(db *sql.DB, zipPath string, validPrefixes []string) error {
+	tradeDate, err := tdx.TickZipDate(zipPath)
+	if err != nil {
+		return err
 	}
-	if err := CreateTable(db, schema); err != nil {
-		return fmt.Errorf("failed to create table: %w", err)
+
+	if err := CreateTable(db, ChangeLogSchema); err != nil {
+		return err
+	}
+	schemas := []TableSchema{TickSchema, OneMinLineSchema, FiveMinLineSchema}
+	for _, schema := range schemas {
+		if err := CreateTable(db, schema); err != nil {
+			return fmt.Errorf("failed to create table: %w", err)
+		}
+		// 重复导入时该交易日的分时整体替换
+		ranges := fmt.Sprintf(`
+			SELECT NULL AS symbol, CAST(? AS DATE) AS from_time, CAST(? AS DATE) + INTERVAL 1 DAY AS to_time
+			FROM %s WHERE CAST(datetime AS DATE) = ?
+			HAVING COUNT(*) > 0
+		`, schema.Name)
+		if err := logChanges(context.Background(), db, schema.Name, ranges, tradeDate, tradeDate, tradeDate); err != nil {
+			return err
+		}
+		query := fmt.Sprintf("DELETE FROM %s WHERE CAST(datetime AS DATE) = ?", schema.Name)
+		if _, err := db.Exec(query, tradeDate); err != nil {
+			return fmt.Errorf("failed to delete %s rows of %s: %w", schema.Name, tradeDate.Format("2006-01-02"), err)
+		}
 	}
 
 	ctx := context.Background()
@@ -106,35 +380,67 @@ func importMinLineFiles(db *sql.DB, schema TableSchema, fileDir string, validPre
 			return fmt.Errorf("unexpected driver conn type %T", dc)
 		}
 
-		appender, err := duckdb.NewAppenderFromConn(driverConn, "", schema.Name)
-		if err != nil {
-			return fmt.Errorf("new appender: %w", err)
-		}
+		appenders := make([]*duckdb.Appender, 0, len(schemas))
 		closed := false
 		defer func() {
 			if closed {
 				return
 			}
-			_ = appender.Close()
+			for _, appender := range appenders {
+				_ = appender.Close()
+			}
 		}()
+		for _, schema := range schemas {
+			appender, err := duckdb.NewAppenderFromConn(driverConn, "", schema.Name)
+			if err != nil {
+				return fmt.Errorf("new appender: %w", err)
+			}
+			appenders = append(appenders, appender)
+		}
+		tickAppender, min1Appender, min5Appender := appenders[0], appenders[1], appenders[2]
 
-		rowValues := make([]driver.Value, 8)
-		if err := tdx.StreamMinFiles(fileDir, validPrefixes, suffix, func(record tdx.MinKlineRecord) error {
-			rowValues[0] = record.Symbol
-			rowValues[1] = record.Open
-			rowValues[2] = record.High
-			rowValues[3] = record.Low
-			rowValues[4] = record.Close
-			rowValues[5] = record.Amount
-			rowValues[6] = record.Volume
-			rowValues[7] = record.Datetime
-			return appender.AppendRow(rowValues...)
+		tickValues := make([]driver.Value, 6)
+		appendBars := func(appender *duckdb.Appender, bars []tdx.MinKlineRecord) error {
+			for _, bar := range bars {
+				if err := appender.AppendRow(bar.Symbol, bar.Open, bar.High, bar.Low, bar.Close, bar.Amount, bar.Volume, bar.Datetime); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if err := tdx.StreamTickZip(zipPath, validPrefixes, func(symbol string, ticks []tdx.TickRecord) error {
+			for _, tick := range ticks {
+				tickValues[0] = tick.Symbol
+				tickValues[1] = tick.Datetime
+				tickValues[2] = tick.Price
+				tickValues[3] = tick.Volume
+				tickValues[4] = tick.Amount
+				tickValues[5] = tick.Direction
+				if err := tickAppender.AppendRow(tickValues...); err != nil {
+					return err
+				}
+			}
+			min1, err := tdx.AggregateTicks(ticks, 1)
+			if err != nil {
+				return err
+			}
+			if err := appendBars(min1Appender, min1); err != nil {
+				return err
+			}
+			min5, err := tdx.AggregateTicks(ticks, 5)
+			if err != nil {
+				return err
+			}
+			return appendBars(min5Appender, min5)
 		}); err != nil {
-			return fmt.Errorf("stream min files: %w", err)
+			return fmt.Errorf("stream tick zip: %w", err)
 		}
 
-		if err := appender.Close(); err != nil {
-			return fmt.Errorf("close appender: %w", err)
+		for _, appender := range appenders {
+			if err := appender.Close(); err != nil {
+				return fmt.Errorf("close appender: %w", err)
+			}
 		}
 		closed = true
 		return nil
@@ -0,0 +1,220 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+)
+
+// LakeColumn 分区列，Expr 由原表字段计算，不写入 Parquet 文件
+type LakeColumn struct {
+	Name string
+	Expr string
+}
+
+// LakeDataset 导出到 Parquet 目录的一张表
+type LakeDataset struct {
+	Name    string // 目录名
+	Table   string
+	Parts   []LakeColumn
+	OrderBy string
+	TimeCol string // 时间列，变更记录在 raw_change_log 中；为空时每次对全部分区计算指纹
+}
+
+// LakePartition 一个分区的行数、最新时间和内容指纹。有 TimeCol 的数据集只统计行数和最新时间，
+// 指纹由 QueryLakeChecksums 按需计算
+type LakePartition struct {
+	Values   []string
+	Rows     int64
+	MaxTime  string
+	Checksum string
+}
+
+var (
+	lakeMarket     = LakeColumn{Name: "market", Expr: "SUBSTR(symbol, 1, 2)"}
+	lakeYear       = LakeColumn{Name: "year", Expr: "strftime(date, '%Y')"}
+	lakeMinYear    = LakeColumn{Name: "year", Expr: "strftime(datetime, '%Y')"}
+	lakeMinMonth   = LakeColumn{Name: "month", Expr: "strftime(datetime, '%m')"}
+	lakeReportYear = LakeColumn{Name: "year", Expr: "strftime(report_date, '%Y')"}
+	lakeRdateYear  = LakeColumn{Name: "year", Expr: "strftime(rdate, '%Y')"}
+)
+
+// LakeDatasets 数据湖包含的表：日线、因子按市场和年分区，分时按市场、年、月分区，
+// 财务和股票数据按年分区
+func LakeDatasets() []LakeDataset {
+	return []LakeDataset{
+		{Name: "stocks_daily", Table: StocksSchema.Name, Parts: []LakeColumn{lakeMarket, lakeYear}, OrderBy: "symbol, date", TimeCol: "date"},
+		{Name: "stocks_1min", Table: OneMinLineSchema.Name, Parts: []LakeColumn{lakeMarket, lakeMinYear, lakeMinMonth}, OrderBy: "symbol, datetime", TimeCol: "datetime"},
+		{Name: "stocks_5min", Table: FiveMinLineSchema.Name, Parts: []LakeColumn{lakeMarket, lakeMinYear, lakeMinMonth}, OrderBy: "symbol, datetime", TimeCol: "datetime"},
+		{Name: "adjust_factor", Table: FactorSchema.Name, Parts: []LakeColumn{lakeMarket, lakeYear}, OrderBy: "symbol, date", TimeCol: "date"},
+		{Name: "caiwu", Table: CaiwuSchema.Name, Parts: []LakeColumn{lakeReportYear}, OrderBy: "code, report_date"},
+		{Name: "gp_base", Table: GpSchema.Name, Parts: []LakeColumn{{Name: "market", Expr: "mkt"}, lakeRdateYear}, OrderBy: "code, rdate"},
+		{Name: "gp_blk", Table: BlkSchema.Name, Parts: []LakeColumn{lakeRdateYear}, OrderBy: "code, rdate"},
+		{Name: "gp_mkt", Table: MktSchema.Name, Parts: []LakeColumn{lakeRdateYear}, OrderBy: "code, rdate"},
+	}
+}
+
+// Path 分区相对目录，如 market=sz/year=2024
+func (ds LakeDataset) Path(values []string) string {
+	segs := make([]string, len(ds.Parts))
+	for i, p := range ds.Parts {
+		segs[i] = fmt.Sprintf("%s=%s", p.Name, values[i])
+	}
+	return strings.Join(segs, "/")
+}
+
+// pathExpr 在 SQL 中生成与 Path 相同的分区目录
+func (ds LakeDataset) pathExpr() string {
+	segs := make([]string, len(ds.Parts))
+	for i, p := range ds.Parts {
+		segs[i] = fmt.Sprintf("'%s=' || CAST(%s AS VARCHAR)", p.Name, p.Expr)
+	}
+	return strings.Join(segs, " || '/' || ")
+}
+
+func (ds LakeDataset) where(values []string) string {
+	conds := make([]string, len(ds.Parts))
+	for i, p := range ds.Parts {
+		conds[i] = fmt.Sprintf("%s = '%s'", p.Expr, strings.ReplaceAll(values[i], "'", "''"))
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// QueryLakePartitions 统计数据集每个分区的行数和最新时间，没有 TimeCol 的数据集同时计算内容指纹。
+// 表不存在时返回 nil
+func QueryLakePartitions(db *sql.DB, ds LakeDataset) ([]LakePartition, error) {
+	cols, err := tableColumns(db, ds.Table)
+	if err != nil {
+		return nil, err
+	}
+	if len(cols) == 0 {
+		return nil, nil
+	}
+
+	exprs := make([]string, len(ds.Parts))
+	for i, p := range ds.Parts {
+		exprs[i] = fmt.Sprintf("CAST(%s AS VARCHAR)", p.Expr)
+	}
+	maxTime, checksum := "''", "CAST(SUM(hash(t)) AS VARCHAR)"
+	if ds.TimeCol != "" {
+		maxTime, checksum = fmt.Sprintf("CAST(MAX(%s) AS VARCHAR)", ds.TimeCol), "''"
+	}
+	query := fmt.Sprintf(`
+		SELECT %[1]s, COUNT(*), %[2]s, %[3]s
+		FROM %[4]s t
+		WHERE %[5]s
+		GROUP BY ALL
+		ORDER BY ALL
+	`, strings.Join(exprs, ", "), maxTime, checksum, ds.Table, strings.Join(notNull(exprs), " AND "))
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query partitions of %s: %w", ds.Table, err)
+	}
+	defer rows.Close()
+
+	parts := []LakePartition{}
+	for rows.Next() {
+		part := LakePartition{Values: make([]string, len(ds.Parts))}
+		dest := make([]any, 0, len(ds.Parts)+3)
+		for i := range part.Values {
+			dest = append(dest, &part.Values[i])
+		}
+		dest = append(dest, &part.Rows, &part.MaxTime, &part.Checksum)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan partition of %s: %w", ds.Table, err)
+		}
+		parts = append(parts, part)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating partitions of %s: %w", ds.Table, err)
+	}
+	return parts, nil
+}
+
+// QueryChangedLakePartitions 返回包含 (since, upto] 批次变更区间内行的分区目录
+func QueryChangedLakePartitions(db *sql.DB, ds LakeDataset, since, upto int64) (map[string]bool, error) {
+	res := map[string]bool{}
+	if ds.TimeCol == "" || upto <= since {
+		return res, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT DISTINCT %s FROM %s t WHERE %s
+	`, ds.pathExpr(), ds.Table, changedRowsFilter(ds.Table, ds.TimeCol, since, upto))
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query changed partitions of %s: %w", ds.Table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan changed partition of %s: %w", ds.Table, err)
+		}
+		res[key] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating changed partitions of %s: %w", ds.Table, err)
+	}
+	return res, nil
+}
+
+// QueryLakeChecksums 只对 keys 中的分区计算内容指纹，返回分区目录到指纹的映射
+func QueryLakeChecksums(db *sql.DB, ds LakeDataset, keys []string) (map[string]string, error) {
+	res := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return res, nil
+	}
+
+	quoted := make([]string, len(keys))
+	for i, k := range keys {
+		quoted[i] = "'" + strings.ReplaceAll(k, "'", "''") + "'"
+	}
+	query := fmt.Sprintf(`
+		SELECT %[1]s AS part, CAST(SUM(hash(t)) AS VARCHAR)
+		FROM %[2]s t
+		WHERE %[1]s IN (%[3]s)
+		GROUP BY part
+	`, ds.pathExpr(), ds.Table, strings.Join(quoted, ", "))
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash partitions of %s: %w", ds.Table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key, checksum string
+		if err := rows.Scan(&key, &checksum); err != nil {
+			return nil, fmt.Errorf("failed to scan checksum of %s: %w", ds.Table, err)
+		}
+		res[key] = checksum
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating checksums of %s: %w", ds.Table, err)
+	}
+	return res, nil
+}
+
+func notNull(exprs []string) []string {
+	res := make([]string, len(exprs))
+	for i, e := range exprs {
+		res[i] = e + " IS NOT NULL"
+	}
+	return res
+}
+
+// ExportLakePartition 把一个分区写成 zstd 压缩的 Parquet 文件
+func ExportLakePartition(db *sql.DB, ds LakeDataset, values []string, path string) error {
+	query := fmt.Sprintf(`
+		COPY (SELECT * FROM %s WHERE %s ORDER BY %s)
+		TO '%s' (FORMAT parquet, COMPRESSION zstd)
+	`, ds.Table, ds.where(values), ds.OrderBy, strings.ReplaceAll(path, "'", "''"))
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to export %s %s: %w", ds.Table, ds.Path(values), err)
+	}
+	return nil
+}
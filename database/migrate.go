@@ -0,0 +1,271 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+)
+
+// 已执行的迁移，最大的 version 即数据库当前的 schema 版本
+var SchemaVersionSchema = TableSchema{
+	Name: "schema_version",
+	Columns: []string{
+		"version INTEGER",
+		"name VARCHAR",
+		"applied_at TIMESTAMP",
+	},
+	Keys: []string{"PRIMARY KEY (version)"},
+}
+
+// Migration 一次 schema 迁移。Up 需可重复执行，中途失败后重跑 migrate 不会出错
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(db *sql.DB) error
+}
+
+// migrations 按 version 递增排列，只能在末尾追加。
+// 表结构描述（cwbase、gpbase 等）新增列时追加一条 syncColumns 迁移
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "add columns missing from existing tables",
+		Up: func(db *sql.DB) error {
+			return syncColumns(db, versionedSchemas()...)
+		},
+	},
+	{
+		Version: 2,
+		Name:    "build raw_capital from raw_gbbq",
+		Up: func(db *sql.DB) error {
+			return migrateCapital(db)
+		},
+	},
+	{
+		Version: 3,
+		Name:    "remove duplicate minute bars",
+		Up: func(db *sql.DB) error {
+			if err := dedupeMinLine(db, OneMinLineSchema); err != nil {
+				return err
+			}
+			return dedupeMinLine(db, FiveMinLineSchema)
+		},
+	},
+}
+
+// versionedSchemas 由迁移维护列的表；raw_base 的列随 dbf 自动增加，不在其中
+func versionedSchemas() []TableSchema {
+	return []TableSchema{
+		StocksSchema, OneMinLineSchema, FiveMinLineSchema, TickSchema,
+		GBBQSchema, CapitalSchema, FactorSchema, FactorStateSchema, FactorHistorySchema,
+		SecuritySchema, SecurityNameEventSchema, SecurityNameHistorySchema,
+		SymbolChangeSchema, SymbolMapSchema, BlockSchema, BlockCfgSchema, DelistSchema,
+		CaiwuSchema, GpSchema, BlkSchema, MktSchema, WorkdaySchema, ChangeLogSchema,
+	}
+}
+
+// LatestSchemaVersion 当前程序对应的 schema 版本
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaVersion 返回数据库的 schema 版本，没有 schema_version 表时为 0
+func SchemaVersion(db *sql.DB) (int, error) {
+	cols, err := tableColumns(db, SchemaVersionSchema.Name)
+	if err != nil {
+		return 0, err
+	}
+	if len(cols) == 0 {
+		return 0, nil
+	}
+
+	var version sql.NullInt64
+	query := fmt.Sprintf("SELECT MAX(version) FROM %s", SchemaVersionSchema.Name)
+	if err := db.QueryRow(query).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to query schema version: %w", err)
+	}
+	return int(version.Int64), nil
+}
+
+// Migrate 依次执行高于当前版本的迁移，返回本次执行的迁移
+func Migrate(db *sql.DB) ([]Migration, error) {
+	if err := CreateTable(db, SchemaVersionSchema); err != nil {
+		return nil, fmt.Errorf("failed to create table: %w", err)
+	}
+
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	if current > LatestSchemaVersion() {
+		return nil, fmt.Errorf("database schema version %d is newer than supported version %d, upgrade tdx2db", current, LatestSchemaVersion())
+	}
+
+	var applied []Migration
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		if err := m.Up(db); err != nil {
+			return applied, fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+		if err := recordMigration(db, m); err != nil {
+			return applied, err
+		}
+		applied = append(applied, m)
+	}
+
+	// 版本已是最新但表结构描述新增了列（忘记追加迁移、或旧版本程序建的表）时同样补齐
+	if err := syncColumns(db, versionedSchemas()...); err != nil {
+		return applied, err
+	}
+	return applied, nil
+}
+
+// RequireSchema 数据库版本与程序一致且已有的表不缺列时返回 nil。
+// 全新的空库直接记为最新版本，旧库需先执行 migrate
+func RequireSchema(db *sql.DB) error {
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	latest := LatestSchemaVersion()
+	switch {
+	case current == latest:
+		missing, err := missingColumns(db, versionedSchemas()...)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("database is missing columns %s, run `tdx2db migrate --dbpath <db>` first", strings.Join(missing, ", "))
+		}
+		return nil
+	case current > latest:
+		return fmt.Errorf("database schema version %d is newer than supported version %d, upgrade tdx2db", current, latest)
+	}
+
+	if current == 0 {
+		empty, err := isEmptyDatabase(db)
+		if err != nil {
+			return err
+		}
+		if empty {
+			if err := CreateTable(db, SchemaVersionSchema); err != nil {
+				return fmt.Errorf("failed to create table: %w", err)
+			}
+			for _, m := range migrations {
+				if err := recordMigration(db, m); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("database schema version %d is older than %d, run `tdx2db migrate --dbpath <db>` first", current, latest)
+}
+
+func recordMigration(db *sql.DB, m Migration) error {
+	query := fmt.Sprintf("INSERT OR REPLACE INTO %s (version, name, applied_at) VALUES (?, ?, now())", SchemaVersionSchema.Name)
+	if _, err := db.Exec(query, m.Version, m.Name); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+	}
+	return nil
+}
+
+func isEmptyDatabase(db *sql.DB) (bool, error) {
+	var n int
+	query := "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = 'main'"
+	if err := db.QueryRow(query).Scan(&n); err != nil {
+		return false, fmt.Errorf("failed to count tables: %w", err)
+	}
+	return n == 0, nil
+}
+
+// missingColumns 返回已存在的表中缺少的列 (table.column)，不存在的表不算缺列
+func missingColumns(db *sql.DB, schemas ...TableSchema) ([]string, error) {
+	var missing []string
+	for _, schema := range schemas {
+		existing, err := tableColumns(db, schema.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) == 0 {
+			continue
+		}
+		for _, colDef := range schema.Columns {
+			name := strings.SplitN(colDef, " ", 2)[0]
+			if !existing[name] {
+				missing = append(missing, schema.Name+"."+name)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// syncColumns 给已存在的表补上表结构描述中新增的列，不存在的表留给各命令按需创建
+func syncColumns(db *sql.DB, schemas ...TableSchema) error {
+	for _, schema := range schemas {
+		existing, err := tableColumns(db, schema.Name)
+		if err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			continue
+		}
+
+		for _, colDef := range schema.Columns {
+			parts := strings.SplitN(colDef, " ", 2)
+			if len(parts) < 2 {
+				return fmt.Errorf("invalid column definition: %s", colDef)
+			}
+			if existing[parts[0]] {
+				continue
+			}
+			query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s", schema.Name, parts[0], parts[1])
+			if _, err := db.Exec(query); err != nil {
+				return fmt.Errorf("failed to add column %s.%s: %w", schema.Name, parts[0], err)
+			}
+			fmt.Printf("🆕 %s 新增列 %s\n", schema.Name, parts[0])
+		}
+	}
+	return nil
+}
+
+// migrateCapital 旧库的 v_turnover 和复权视图仍基于窗口计算，建好 raw_capital 后重建这些视图
+func migrateCapital(db *sql.DB) error {
+	for _, schema := range []TableSchema{GBBQSchema, StocksSchema} {
+		cols, err := tableColumns(db, schema.Name)
+		if err != nil {
+			return err
+		}
+		if len(cols) == 0 {
+			return nil
+		}
+	}
+
+	if err := BuildCapital(db); err != nil {
+		return err
+	}
+	if err := CreateTurnoverView(db); err != nil {
+		return err
+	}
+
+	cols, err := tableColumns(db, FactorSchema.Name)
+	if err != nil {
+		return err
+	}
+	if len(cols) == 0 {
+		return nil
+	}
+	if err := CreateQfqView(db); err != nil {
+		return err
+	}
+	if err := CreateHfqView(db); err != nil {
+		return err
+	}
+	return CreateAdjustViews(db)
+}
@@ -28,6 +28,18 @@ var FiveMinLineSchema = TableSchema{
 	Columns: minLineColumns,
 }
 
+var TickSchema = TableSchema{
+	Name: "raw_stocks_tick",
+	Columns: []string{
+		"symbol VARCHAR",
+		"datetime TIMESTAMP",
+		"price DOUBLE",
+		"volume BIGINT",
+		"amount DOUBLE",
+		"direction TINYINT",
+	},
+}
+
 func Import1MinLineCsv(db *sql.DB, csvPath string) error {
 	if err := CreateTable(db, OneMinLineSchema); err != nil {
 		return fmt.Errorf("failed to create table: %w", err)
@@ -51,3 +63,66 @@ func Import5MinLineCsv(db *sql.DB, csvPath string) error {
 
 	return nil
 }
+
+var Qfq1MinViewName = "v_qfq_1min"
+var Hfq1MinViewName = "v_hfq_1min"
+var Qfq5MinViewName = "v_qfq_5min"
+var Hfq5MinViewName = "v_hfq_5min"
+
+func minAdjustViewSQL(name string, schema TableSchema, factor string) string {
+	return fmt.Sprintf(`
+	CREATE OR REPLACE VIEW %[1]s AS
+	SELECT
+		m.symbol,
+		m.datetime,
+		CAST(ROUND(m.volume / NULLIF(f.%[2]s, 0)) AS BIGINT) AS volume,
+		m.amount,
+		ROUND(m.open  * f.%[2]s, 4) AS open,
+		ROUND(m.high  * f.%[2]s, 4) AS high,
+		ROUND(m.low   * f.%[2]s, 4) AS low,
+		ROUND(m.close * f.%[2]s, 4) AS close
+	FROM %[3]s m
+	JOIN %[4]s f ON m.symbol = f.symbol AND CAST(m.datetime AS DATE) = f.date;
+	`, name, factor, schema.Name, FactorSchema.Name)
+}
+
+// createMinAdjustView 分时复权视图：按 K 线所属交易日关联当日的日线复权因子。
+// 因子以交易日为键，除权日开盘的第一根 K 线即使用当日除权后的因子，
+// 日线尚未导入的交易日没有因子，对应的分时 K 线不出现在视图中
+func createMinAdjustView(db *sql.DB, name string, schema TableSchema, factor string) error {
+	query := minAdjustViewSQL(name, schema, factor)
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to create or replace view %s: %w", name, err)
+	}
+	return nil
+}
+
+// CreateMinAdjustViews 为已导入的 1/5 分钟表创建前、后复权视图，分时表不存在时跳过
+func CreateMinAdjustViews(db *sql.DB) error {
+	views := []struct {
+		schema TableSchema
+		qfq    string
+		hfq    string
+	}{
+		{OneMinLineSchema, Qfq1MinViewName, Hfq1MinViewName},
+		{FiveMinLineSchema, Qfq5MinViewName, Hfq5MinViewName},
+	}
+
+	for _, v := range views {
+		columns, err := tableColumns(db, v.schema.Name)
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		if err := createMinAdjustView(db, v.qfq, v.schema, "qfq_factor"); err != nil {
+			return err
+		}
+		if err := createMinAdjustView(db, v.hfq, v.schema, "hfq_factor"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+)
+
+// 计算的前收盘价与 TNF 中交易所公布的前收盘价的对比结果，每次检查重建
+var PreCloseCheckSchema = TableSchema{
+	Name: "raw_preclose_check",
+	Columns: []string{
+		"symbol VARCHAR",
+		"date DATE /*TNF 行情日期*/",
+		"tdx_prev_close DOUBLE /*TNF 前收盘价*/",
+		"pre_close DOUBLE /*raw_adjust_factor 计算的前收盘价*/",
+		"diff DOUBLE /*pre_close - tdx_prev_close*/",
+		"has_event BOOLEAN /*当日有除权除息或扩缩股事件*/",
+		"mismatch BOOLEAN /*差异超过容差*/",
+		"missing BOOLEAN /*当日有日线但 raw_adjust_factor 没有前收盘价*/",
+	},
+	Keys: []string{"PRIMARY KEY (symbol)"},
+}
+
+// PreCloseMismatch 一条前收盘价不一致的记录
+type PreCloseMismatch struct {
+	Symbol       string
+	Date         time.Time
+	TdxPrevClose float64
+	PreClose     float64
+	Diff         float64
+	HasEvent     bool
+}
+
+// PreCloseCheckResult 前收盘价检查汇总，Missing 为当日有日线却没有计算出前收盘价的股票
+type PreCloseCheckResult struct {
+	Checked    int
+	Mismatches []PreCloseMismatch
+	Missing    []string
+}
+
+// CheckPreClose 在 TNF 行情日期上对比 raw_security.prev_close 和 raw_adjust_factor.pre_close，
+// 绝对差超过 tolerance 记为不一致。不一致通常意味着股本变迁缺少或录错了事件。
+// 只检查当日有日线的股票（停牌的跳过），其中因子缺失的记为 missing
+func CheckPreClose(db *sql.DB, tolerance float64) (*PreCloseCheckResult, error) {
+	required := []struct {
+		schema TableSchema
+		cmd    string
+	}{
+		{SecuritySchema, "base"},
+		{StocksSchema, "cron"},
+		{GBBQSchema, "cron"},
+		{FactorSchema, "cron"},
+	}
+	for _, r := range required {
+		cols, err := tableColumns(db, r.schema.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(cols) == 0 {
+			return nil, fmt.Errorf("table %s not found, run %s first", r.schema.Name, r.cmd)
+		}
+	}
+	if err := CreateXdxrView(db); err != nil {
+		return nil, err
+	}
+
+	if err := DropTable(db, PreCloseCheckSchema); err != nil {
+		return nil, fmt.Errorf("failed to drop table: %w", err)
+	}
+	if err := CreateTable(db, PreCloseCheckSchema); err != nil {
+		return nil, fmt.Errorf("failed to create table: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (symbol, date, tdx_prev_close, pre_close, diff, has_event, mismatch, missing)
+		WITH x AS (SELECT DISTINCT code, date FROM %[4]s)
+		SELECT
+			s.symbol,
+			s.date,
+			s.prev_close,
+			f.pre_close,
+			ROUND(f.pre_close - s.prev_close, 4),
+			x.code IS NOT NULL,
+			COALESCE(ABS(f.pre_close - s.prev_close) > %[5]g, FALSE),
+			COALESCE(f.pre_close, 0) <= 0
+		FROM %[2]s s
+		JOIN %[6]s d ON d.symbol = s.symbol AND d.date = s.date
+		LEFT JOIN %[3]s f ON f.symbol = s.symbol AND f.date = s.date
+		LEFT JOIN x ON x.code = s.code AND x.date = s.date
+		WHERE s.prev_close > 0
+	`, PreCloseCheckSchema.Name, SecuritySchema.Name, FactorSchema.Name, XdxrViewName, tolerance, StocksSchema.Name)
+	if _, err := db.Exec(query); err != nil {
+		return nil, fmt.Errorf("failed to check pre_close: %w", err)
+	}
+
+	res := &PreCloseCheckResult{}
+	query = fmt.Sprintf("SELECT COUNT(*) FROM %s", PreCloseCheckSchema.Name)
+	if err := db.QueryRow(query).Scan(&res.Checked); err != nil {
+		return nil, fmt.Errorf("failed to count %s: %w", PreCloseCheckSchema.Name, err)
+	}
+
+	query = fmt.Sprintf(`
+		SELECT symbol, date, tdx_prev_close, pre_close, diff, has_event
+		FROM %s
+		WHERE mismatch
+		ORDER BY ABS(diff / tdx_prev_close) DESC, symbol
+	`, PreCloseCheckSchema.Name)
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pre_close mismatches: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m PreCloseMismatch
+		if err := rows.Scan(&m.Symbol, &m.Date, &m.TdxPrevClose, &m.PreClose, &m.Diff, &m.HasEvent); err != nil {
+			return nil, fmt.Errorf("failed to scan pre_close mismatch: %w", err)
+		}
+		res.Mismatches = append(res.Mismatches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pre_close mismatches: %w", err)
+	}
+
+	query = fmt.Sprintf("SELECT symbol FROM %s WHERE missing ORDER BY symbol", PreCloseCheckSchema.Name)
+	missing, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query symbols missing pre_close: %w", err)
+	}
+	defer missing.Close()
+
+	for missing.Next() {
+		var symbol string
+		if err := missing.Scan(&symbol); err != nil {
+			return nil, fmt.Errorf("failed to scan symbol missing pre_close: %w", err)
+		}
+		res.Missing = append(res.Missing, symbol)
+	}
+	if err := missing.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating symbols missing pre_close: %w", err)
+	}
+
+	return res, nil
+}
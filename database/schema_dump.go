@@ -0,0 +1,181 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:generate go run .. dump-schema --output sql
+
+// schemaFiles 编译时嵌入的 database/sql 文件，由 dump-schema --output 生成
+//
+//go:embed sql/*.sql
+var schemaFiles embed.FS
+
+const schemaHeader = "-- Generated by `tdx2db dump-schema --output database/sql`, do not edit\n-- Dialect: DuckDB\n"
+
+// schemaObject 一张表、一个视图或表宏的定义
+type schemaObject struct {
+	name string
+	desc string
+	ddl  string
+}
+
+// SchemaScript database/sql 下的一个 DDL 文件
+type SchemaScript struct {
+	File    string
+	objects []schemaObject
+}
+
+// Render 生成文件内容
+func (s SchemaScript) Render() string {
+	var sb strings.Builder
+	sb.WriteString(schemaHeader)
+	for _, obj := range s.objects {
+		sb.WriteString("\n-- ")
+		sb.WriteString(obj.name)
+		if obj.desc != "" {
+			sb.WriteString(": ")
+			sb.WriteString(obj.desc)
+		}
+		sb.WriteString("\n")
+		sb.WriteString(dedentSQL(obj.ddl))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// schemaTables 所有由 TableSchema 定义的表，按 schema_tables.sql 中的顺序
+func schemaTables() []TableSchema {
+	return []TableSchema{
+		SchemaVersionSchema, WorkdaySchema, BaseSchema, BlockCfgSchema, BlockSchema, DelistSchema,
+		SecuritySchema, SecurityNameEventSchema, SecurityNameHistorySchema,
+		CaiwuSchema, GpSchema, MktSchema, BlkSchema,
+		FactorSchema, FactorHistorySchema, FactorStateSchema, FactorVerifySchema, PreCloseCheckSchema,
+		SymbolChangeSchema, SymbolMapSchema,
+		StocksSchema, GBBQSchema, CapitalSchema, OneMinLineSchema, FiveMinLineSchema, TickSchema, ChangeLogSchema,
+	}
+}
+
+func columnViewObjects(views []ColumnViews) []schemaObject {
+	objs := make([]schemaObject, len(views))
+	for i, v := range views {
+		objs[i] = schemaObject{name: v.name, desc: v.desc, ddl: viewDDL(v)}
+	}
+	return objs
+}
+
+// SchemaScripts 由 Go 中的表结构和视图定义生成的全部 DDL 文件，按依赖顺序排列，依次执行即可建出完整的库
+func SchemaScripts() []SchemaScript {
+	var tables []schemaObject
+	for _, t := range schemaTables() {
+		tables = append(tables, schemaObject{name: t.Name, ddl: tableDDL(t)})
+	}
+
+	stock := []schemaObject{
+		{name: QfqViewName, desc: "前复权日线", ddl: qfqViewSQL()},
+		{name: HfqViewName, desc: "后复权日线", ddl: hfqViewSQL()},
+		{name: AdjustOffsetViewName, desc: "等差复权偏移量", ddl: adjustOffsetViewSQL()},
+		{name: QfqAddViewName, desc: "等差前复权日线", ddl: addViewSQL(QfqAddViewName, "qfq_offset", "qfq_factor")},
+		{name: HfqAddViewName, desc: "等差后复权日线", ddl: addViewSQL(HfqAddViewName, "hfq_offset", "hfq_factor")},
+		{name: TotalReturnViewName, desc: "全收益序列", ddl: totalReturnViewSQL()},
+		{name: AdjustMacroName, desc: "任意锚定日复权", ddl: adjustMacroSQL()},
+		{name: FactorAsofMacroName, desc: "按日期回看的复权因子", ddl: factorAsofMacroSQL()},
+		{name: QfqAsofMacroName, desc: "按日期回看的前复权日线", ddl: qfqAsofMacroSQL()},
+		{name: Qfq1MinViewName, desc: "前复权 1 分钟线", ddl: minAdjustViewSQL(Qfq1MinViewName, OneMinLineSchema, "qfq_factor")},
+		{name: Hfq1MinViewName, desc: "后复权 1 分钟线", ddl: minAdjustViewSQL(Hfq1MinViewName, OneMinLineSchema, "hfq_factor")},
+		{name: Qfq5MinViewName, desc: "前复权 5 分钟线", ddl: minAdjustViewSQL(Qfq5MinViewName, FiveMinLineSchema, "qfq_factor")},
+		{name: Hfq5MinViewName, desc: "后复权 5 分钟线", ddl: minAdjustViewSQL(Hfq5MinViewName, FiveMinLineSchema, "hfq_factor")},
+		{name: FactorEventsViewName, desc: "复权因子变化及对应的除权除息事件", ddl: factorEventsViewSQL()},
+	}
+
+	gbbq := append([]schemaObject{
+		{name: XdxrViewName, desc: "除权除息 / 分红送配 / 扩缩股", ddl: xdxrViewSQL()},
+		{name: TurnoverViewName, desc: "换手率 / 市值", ddl: turnoverViewSQL()},
+	}, columnViewObjects(gbbqViews)...)
+
+	symbol := []schemaObject{
+		{name: SecurityNameDailyViewName, desc: "每个交易日有效的名称和 ST 状态", ddl: securityNameDailyViewSQL()},
+	}
+	for _, v := range stitchedViews() {
+		symbol = append(symbol, schemaObject{name: v.name, desc: "按经济实体拼接", ddl: v.ddl()})
+	}
+
+	return []SchemaScript{
+		{File: "schema_tables.sql", objects: tables},
+		{File: "view_gbbq.sql", objects: gbbq},
+		{File: "view_stock.sql", objects: stock},
+		{File: "view_symbol.sql", objects: symbol},
+		{File: "view_cw.sql", objects: columnViewObjects(cwViews)},
+		{File: "view_gp.sql", objects: columnViewObjects(gpViews)},
+		{File: "view_blk.sql", objects: columnViewObjects(blkViews)},
+		{File: "view_mkt.sql", objects: columnViewObjects(mktViews)},
+	}
+}
+
+// DiffSchemaFiles 比较嵌入的 .sql 文件和生成结果，返回内容不一致、缺失或多余的文件名
+func DiffSchemaFiles() ([]string, error) {
+	embedded := make(map[string]string)
+	err := fs.WalkDir(schemaFiles, "sql", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := schemaFiles.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		embedded[path.Base(p)] = string(data)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded schema files: %w", err)
+	}
+
+	var diff []string
+	for _, s := range SchemaScripts() {
+		if embedded[s.File] != s.Render() {
+			diff = append(diff, s.File)
+		}
+		delete(embedded, s.File)
+	}
+	for file := range embedded {
+		diff = append(diff, file)
+	}
+	sort.Strings(diff)
+	return diff, nil
+}
+
+// dedentSQL 去掉 Go 源码中的缩进：删除首尾空行和公共前缀空白，行首 tab 换成 4 个空格
+func dedentSQL(ddl string) string {
+	lines := strings.Split(strings.Trim(ddl, "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	prefix := ""
+	first := true
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		indent := l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+		if first {
+			prefix, first = indent, false
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	for i, l := range lines {
+		l = strings.TrimRight(strings.TrimPrefix(l, prefix), " \t")
+		body := strings.TrimLeft(l, "\t")
+		lines[i] = strings.Repeat("    ", len(l)-len(body)) + body
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,14 @@
+package database
+
+import "testing"
+
+// TestSchemaFilesUpToDate 修改表结构或视图后需运行 go generate ./database 更新 database/sql
+func TestSchemaFilesUpToDate(t *testing.T) {
+	diff, err := DiffSchemaFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) > 0 {
+		t.Fatalf("database/sql is out of date, run go generate ./database: %v", diff)
+	}
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/jing2uo/tdx2db/tdx"
+)
+
+var SecuritySchema = TableSchema{
+	Name: "raw_security",
+	Columns: []string{
+		"symbol VARCHAR",
+		"code VARCHAR",
+		"mkt VARCHAR",
+		"name VARCHAR",
+		"pinyin VARCHAR /*拼音简称*/",
+		"tnf_type TINYINT /*2 指数/股票 3 ETF/基金/b股 4 债券*/",
+		"subtype VARCHAR /*ashare etf lof kzz reits bshare index ...*/",
+		"scaling DOUBLE /*价格精度*/",
+		"prev_close DOUBLE /*最新交易日的前收盘价*/",
+		"date DATE /*行情日期*/",
+	},
+	Keys: []string{"PRIMARY KEY (symbol)"},
+}
+
+var securityColumnNames = []string{
+	"symbol", "code", "mkt", "name", "pinyin", "tnf_type", "subtype", "scaling", "prev_close", "date",
+}
+
+// SecurityRecord TNF 证券信息及 parseCode 细分类型
+type SecurityRecord struct {
+	*tdx.StockRecord
+	Subtype string
+}
+
+func ImportSecurity(db *sql.DB, recs []SecurityRecord) error {
+	//每次导入都重新建表
+	if err := DropTable(db, SecuritySchema); err != nil {
+		return fmt.Errorf("failed to drop table: %w", err)
+	}
+
+	if err := CreateTable(db, SecuritySchema); err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "tdx-security.csv")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	writer := csv.NewWriter(tmpFile)
+	if err := writer.Write(securityColumnNames); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	seen := make(map[string]bool, len(recs))
+	for _, record := range recs {
+		symbol := record.Mkt + record.Code
+		if record.Code == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+
+		row := []string{
+			symbol,
+			record.Code,
+			record.Mkt,
+			record.Name,
+			record.NamePinyin,
+			strconv.Itoa(int(record.Typ)),
+			record.Subtype,
+			strconv.FormatFloat(record.Scaling, 'f', -1, 64),
+			strconv.FormatFloat(float64(record.PrevClose), 'f', -1, 32),
+			record.Date.Format("2006-01-02"),
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row for %s: %w", symbol, err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush CSV writer: %w", err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temp CSV: %w", err)
+	}
+
+	if err := ImportCSV(db, SecuritySchema, tmpFile.Name()); err != nil {
+		return fmt.Errorf("failed to import CSV: %s %w", tmpFile.Name(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+)
+
+// 证券名称 / ST 状态变更事件，来源：
+//   - tnf: base 读取的 TNF 快照，名称与上一次记录不同时追加
+//   - gp:  raw_gp_base.f291 更名标志 1 更名 2 ST 3 *ST 4 摘帽
+var SecurityNameEventSchema = TableSchema{
+	Name: "raw_security_name_event",
+	Columns: []string{
+		"symbol VARCHAR",
+		"date DATE",
+		"name VARCHAR /*为空表示该事件不含名称*/",
+		"st_flag VARCHAR /*ST、*ST 或空串，为 NULL 表示该事件不含 ST 信息*/",
+		"source VARCHAR /*tnf 或 gp*/",
+	},
+	Keys: []string{"PRIMARY KEY (symbol, date, source)"},
+}
+
+var SecurityNameHistorySchema = TableSchema{
+	Name: "raw_security_name_history",
+	Columns: []string{
+		"symbol VARCHAR",
+		"valid_from DATE",
+		"valid_to DATE /*为 NULL 表示至今有效*/",
+		"name VARCHAR",
+		"st_flag VARCHAR",
+	},
+}
+
+var SecurityNameDailyViewName = "v_security_name_daily"
+
+// stFlagExpr 从证券名称推断 ST 状态
+const stFlagExpr = `CASE WHEN %[1]s LIKE '%%*ST%%' THEN '*ST' WHEN %[1]s LIKE '%%ST%%' THEN 'ST' ELSE '' END`
+
+func ensureSecurityNameTables(db *sql.DB) error {
+	for _, schema := range []TableSchema{SecurityNameEventSchema, SecurityNameHistorySchema} {
+		if err := CreateTable(db, schema); err != nil {
+			return fmt.Errorf("failed to create table: %w", err)
+		}
+	}
+	return nil
+}
+
+// ImportTnfNameEvents 将 raw_security 中名称发生变化的证券记为一次 tnf 事件
+func ImportTnfNameEvents(db *sql.DB) error {
+	if err := ensureSecurityNameTables(db); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		INSERT OR REPLACE INTO %s (symbol, date, name, st_flag, source)
+		SELECT s.symbol, s.date, s.name, %s, 'tnf'
+		FROM %s s
+		LEFT JOIN %s h ON h.symbol = s.symbol AND h.valid_to IS NULL
+		WHERE s.date > DATE '1990-01-01'
+		  AND s.name <> ''
+		  AND h.name IS DISTINCT FROM s.name
+	`, SecurityNameEventSchema.Name, fmt.Sprintf(stFlagExpr, "s.name"), SecuritySchema.Name, SecurityNameHistorySchema.Name)
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to import tnf name events: %w", err)
+	}
+	return nil
+}
+
+// ImportGpNameEvents 将 raw_gp_base 的更名标志 (f291) 记为 gp 事件
+func ImportGpNameEvents(db *sql.DB) error {
+	if err := ensureSecurityNameTables(db); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		INSERT OR REPLACE INTO %s (symbol, date, name, st_flag, source)
+		SELECT mkt || code, rdate, NULL,
+			CASE CAST(f291 AS INTEGER)
+				WHEN 2 THEN 'ST'
+				WHEN 3 THEN '*ST'
+				WHEN 4 THEN ''
+			END,
+			'gp'
+		FROM %s
+		WHERE CAST(f291 AS INTEGER) IN (1, 2, 3, 4)
+	`, SecurityNameEventSchema.Name, GpSchema.Name)
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to import gp name events: %w", err)
+	}
+	return nil
+}
+
+// RebuildSecurityNameHistory 由事件表重建名称区间表，并更新按日名称视图。
+// 同一天的事件合并，ST 状态以 gp 标志优先；名称和 ST 状态在事件缺失时沿用上一区间。
+func RebuildSecurityNameHistory(db *sql.DB) error {
+	if err := ensureSecurityNameTables(db); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		CREATE OR REPLACE TABLE %s AS
+		WITH ev AS (
+			SELECT
+				symbol,
+				date,
+				MAX(name) AS name,
+				COALESCE(
+					MAX(st_flag) FILTER (WHERE source = 'gp'),
+					MAX(st_flag) FILTER (WHERE source = 'tnf')
+				) AS st_flag
+			FROM %s
+			GROUP BY symbol, date
+		)
+		SELECT
+			symbol,
+			date AS valid_from,
+			CAST(LEAD(date) OVER w - INTERVAL 1 DAY AS DATE) AS valid_to,
+			LAST_VALUE(name IGNORE NULLS) OVER (w ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS name,
+			LAST_VALUE(st_flag IGNORE NULLS) OVER (w ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS st_flag
+		FROM ev
+		WINDOW w AS (PARTITION BY symbol ORDER BY date)
+		ORDER BY symbol, valid_from
+	`, SecurityNameHistorySchema.Name, SecurityNameEventSchema.Name)
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to rebuild %s: %w", SecurityNameHistorySchema.Name, err)
+	}
+
+	return CreateSecurityNameDailyView(db)
+}
+
+func securityNameDailyViewSQL() string {
+	return fmt.Sprintf(`
+	CREATE OR REPLACE VIEW %s AS
+	SELECT
+		s.symbol,
+		s.date,
+		h.name,
+		COALESCE(h.st_flag, '') AS st_flag,
+		COALESCE(h.st_flag, '') <> '' AS is_st
+	FROM %s s
+	LEFT JOIN %s h
+		ON s.symbol = h.symbol
+		AND s.date >= h.valid_from
+		AND (h.valid_to IS NULL OR s.date <= h.valid_to);
+	`, SecurityNameDailyViewName, StocksSchema.Name, SecurityNameHistorySchema.Name)
+}
+
+// CreateSecurityNameDailyView 按交易日给出当日有效的名称和 ST 状态，无前视偏差
+func CreateSecurityNameDailyView(db *sql.DB) error {
+	if err := CreateTable(db, StocksSchema); err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	query := securityNameDailyViewSQL()
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to create or replace view %s: %w", SecurityNameDailyViewName, err)
+	}
+	return nil
+}
@@ -0,0 +1,384 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SinkStateSchema 目标库中每张增量同步的表已处理到的 raw_change_log 批次
+var SinkStateSchema = TableSchema{
+	Name: "raw_sink_state",
+	Columns: []string{
+		"table_name VARCHAR",
+		"version BIGINT",
+		"synced_at TIMESTAMP",
+	},
+	Keys: []string{"PRIMARY KEY (table_name)"},
+}
+
+// SinkTable 同步到外部后端的表。TimeCol 非空时按 (symbol, TimeCol) 增量同步：
+// raw_change_log 中记录的修订区间在目标库更新或删除，晚于目标库每个 symbol 最新时间的行追加；
+// TimeCol 为空时写入暂存表后整表替换
+type SinkTable struct {
+	Schema  TableSchema
+	TimeCol string
+}
+
+// SinkView 在目标库中创建的视图，只使用各后端通用的 SQL
+type SinkView struct {
+	Name  string
+	Query string
+}
+
+// SinkSet 一组随某个命令同步的表和视图
+type SinkSet struct {
+	Tables []SinkTable
+	Views  []SinkView
+}
+
+// sinkAdjustQuery 复权视图，价格不做四舍五入，由使用方按需处理
+func sinkAdjustQuery(factor string) string {
+	return fmt.Sprintf(`
+		SELECT
+			s.symbol,
+			s.date,
+			CAST(ROUND(s.volume / NULLIF(f.%[1]s, 0)) AS BIGINT) AS volume,
+			s.amount,
+			s.open  * f.%[1]s AS open,
+			s.high  * f.%[1]s AS high,
+			s.low   * f.%[1]s AS low,
+			s.close * f.%[1]s AS close
+		FROM %[2]s s
+		JOIN %[3]s f ON s.symbol = f.symbol AND s.date = f.date
+	`, factor, StocksSchema.Name, FactorSchema.Name)
+}
+
+// SinkSets cron、cw、gp 命令各自同步的数据
+func SinkSets() map[string]SinkSet {
+	return map[string]SinkSet{
+		"cron": {
+			Tables: []SinkTable{
+				{Schema: StocksSchema, TimeCol: "date"},
+				{Schema: OneMinLineSchema, TimeCol: "datetime"},
+				{Schema: FiveMinLineSchema, TimeCol: "datetime"},
+				{Schema: GBBQSchema},
+				{Schema: FactorSchema},
+			},
+			Views: []SinkView{
+				{Name: QfqViewName, Query: sinkAdjustQuery("qfq_factor")},
+				{Name: HfqViewName, Query: sinkAdjustQuery("hfq_factor")},
+			},
+		},
+		"cw": {
+			Tables: []SinkTable{{Schema: CaiwuSchema}},
+		},
+		"gp": {
+			Tables: []SinkTable{{Schema: GpSchema}, {Schema: BlkSchema}, {Schema: MktSchema}},
+		},
+	}
+}
+
+// SyncStats 单张表的同步结果，Rows 为追加或整表替换写入的行数
+type SyncStats struct {
+	Table    string
+	Rows     int64
+	Updated  int64 // 修订区间内更新或插入的行数
+	Deleted  int64 // 修订区间内源表已不存在的行数
+	Replaced bool
+	Skipped  bool // 源表不存在
+}
+
+// SyncToBackend 把 DuckDB 中的表同步到 dst 并重建视图，视图依赖的表全部存在时才创建
+func SyncToBackend(db *sql.DB, dst Backend, set SinkSet) ([]SyncStats, error) {
+	ctx := context.Background()
+	synced := make(map[string]bool)
+	version, err := LatestChangeVersion(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []SyncStats
+	for _, t := range set.Tables {
+		cols, err := tableColumns(db, t.Schema.Name)
+		if err != nil {
+			return stats, err
+		}
+		if len(cols) == 0 {
+			stats = append(stats, SyncStats{Table: t.Schema.Name, Skipped: true})
+			continue
+		}
+
+		var st SyncStats
+		if t.TimeCol != "" {
+			st, err = syncIncremental(ctx, db, dst, t, version)
+		} else {
+			st, err = syncReplace(ctx, db, dst, t.Schema)
+		}
+		if err != nil {
+			return stats, fmt.Errorf("failed to sync %s to %s: %w", t.Schema.Name, dst.Name(), err)
+		}
+		stats = append(stats, st)
+		synced[t.Schema.Name] = true
+	}
+
+	for _, v := range set.Views {
+		ready := true
+		for _, t := range set.Tables {
+			if strings.Contains(v.Query, t.Schema.Name) && !synced[t.Schema.Name] {
+				ready = false
+			}
+		}
+		if !ready {
+			continue
+		}
+		if err := dst.CreateView(v.Name, v.Query); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// syncIncremental 目标库还没有同步记录、源库批次回退（库重建）或期间有整表重算时整表替换，
+// 否则先同步 raw_change_log 中 (已同步批次, version] 的修订区间，再追加新行，最后记下 version。
+// 中途失败时批次不更新，再次运行会重做同样的区间
+func syncIncremental(ctx context.Context, db *sql.DB, dst Backend, t SinkTable, version int64) (SyncStats, error) {
+	applied, ok, err := sinkVersion(dst, t.Schema.Name)
+	if err != nil {
+		return SyncStats{Table: t.Schema.Name}, err
+	}
+	rebuilt := !ok || applied > version
+	if !rebuilt && version > applied {
+		if rebuilt, err = tableRebuilt(db, t.Schema.Name, applied, version); err != nil {
+			return SyncStats{Table: t.Schema.Name}, err
+		}
+	}
+
+	var st SyncStats
+	if rebuilt {
+		// 替换后的表没有唯一索引，需要重建
+		if st, err = syncReplace(ctx, db, dst, t.Schema); err == nil {
+			err = createSinkKey(dst, t)
+		}
+	} else {
+		st, err = syncChanges(ctx, db, dst, t, applied, version)
+	}
+	if err != nil {
+		return st, err
+	}
+	return st, saveSinkVersion(ctx, dst, t.Schema.Name, version)
+}
+
+// syncChanges 读取目标库每个 symbol 的最新时间作为水位。水位以内的修订区间：源表的行写入暂存表后按
+// (symbol, TimeCol) 更新到目标表，目标表中源表已没有的行删除；水位之后的行直接追加
+func syncChanges(ctx context.Context, db *sql.DB, dst Backend, t SinkTable, since, upto int64) (SyncStats, error) {
+	st := SyncStats{Table: t.Schema.Name}
+	if err := dst.CreateTable(t.Schema); err != nil {
+		return st, err
+	}
+	if err := createSinkKey(dst, t); err != nil {
+		return st, err
+	}
+	latest, err := dst.LatestDateBySymbol(t.Schema.Name, t.TimeCol)
+	if err != nil {
+		return st, err
+	}
+
+	// 水位写入 DuckDB 的临时表，在库内过滤
+	wm := TableSchema{
+		Name:    t.Schema.Name + "_sink_wm",
+		Columns: []string{"symbol VARCHAR", "wm TIMESTAMP"},
+	}
+	if err := DropTable(db, wm); err != nil {
+		return st, err
+	}
+	if err := CreateTable(db, wm); err != nil {
+		return st, err
+	}
+	defer DropTable(db, wm)
+
+	if _, err := NewDuckDBBackend(db).Append(ctx, wm, func(emit func(row ...any) error) error {
+		for symbol, ts := range latest {
+			if err := emit(symbol, ts); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return st, err
+	}
+
+	names := schemaColumnNames(t.Schema)
+	if upto > since {
+		if st.Updated, st.Deleted, err = syncRevisions(ctx, db, dst, t, wm.Name, since, upto); err != nil {
+			return st, err
+		}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT t.%[1]s
+		FROM %[2]s t
+		LEFT JOIN %[3]s w ON w.symbol = t.symbol
+		WHERE w.symbol IS NULL OR t.%[4]s > w.wm
+		ORDER BY t.symbol, t.%[4]s
+	`, strings.Join(names, ", t."), t.Schema.Name, wm.Name, t.TimeCol)
+
+	st.Rows, err = dst.Append(ctx, t.Schema, querySource(db, query, len(names)))
+	return st, err
+}
+
+// syncRevisions 把变更区间和区间内源表的行分别写入目标库的 _ranges、_stage 表，
+// 更新暂存的行并删除区间内暂存表中没有的行
+func syncRevisions(ctx context.Context, db *sql.DB, dst Backend, t SinkTable, wm string, since, upto int64) (int64, int64, error) {
+	timeType := "TIMESTAMP"
+	for _, colDef := range t.Schema.Columns {
+		if strings.SplitN(colDef, " ", 2)[0] == t.TimeCol {
+			timeType = columnType(colDef)
+		}
+	}
+	ranges := TableSchema{
+		Name:    t.Schema.Name + "_ranges",
+		Columns: []string{"symbol VARCHAR", "from_time " + timeType, "to_time " + timeType},
+	}
+	stage := TableSchema{Name: t.Schema.Name + "_stage", Columns: t.Schema.Columns}
+	for _, s := range []TableSchema{ranges, stage} {
+		if err := dst.DropTable(s); err != nil {
+			return 0, 0, err
+		}
+		if err := dst.CreateTable(s); err != nil {
+			return 0, 0, err
+		}
+		defer dst.DropTable(s)
+	}
+
+	query := changeRangesQuery(t.Schema.Name, timeType, since, upto)
+	if _, err := dst.Append(ctx, ranges, querySource(db, query, len(ranges.Columns))); err != nil {
+		return 0, 0, err
+	}
+
+	names := schemaColumnNames(t.Schema)
+	query = fmt.Sprintf(`
+		SELECT t.%[1]s
+		FROM %[2]s t
+		JOIN %[3]s w ON w.symbol = t.symbol
+		WHERE t.%[4]s <= w.wm AND %[5]s
+	`, strings.Join(names, ", t."), t.Schema.Name, wm, t.TimeCol, changedRowsFilter(t.Schema.Name, t.TimeCol, since, upto))
+	if _, err := dst.Append(ctx, stage, querySource(db, query, len(names))); err != nil {
+		return 0, 0, err
+	}
+
+	updated, err := dst.Upsert(ctx, t.Schema, []string{"symbol", t.TimeCol}, stage.Name)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// 不用别名，SQLite 的 DELETE 不支持表别名
+	query = fmt.Sprintf(`
+		DELETE FROM %[1]s
+		WHERE EXISTS (
+			SELECT 1 FROM %[2]s r
+			WHERE (r.symbol IS NULL OR r.symbol = %[1]s.symbol)
+			  AND (r.from_time IS NULL OR %[1]s.%[4]s >= r.from_time)
+			  AND (r.to_time IS NULL OR %[1]s.%[4]s <= r.to_time)
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM %[3]s s WHERE s.symbol = %[1]s.symbol AND s.%[4]s = %[1]s.%[4]s
+		)
+	`, t.Schema.Name, ranges.Name, stage.Name, t.TimeCol)
+	res, err := dst.DB().ExecContext(ctx, query)
+	if err != nil {
+		return 0, 0, fmt.Errorf("delete revised rows from %s: %w", t.Schema.Name, err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, 0, fmt.Errorf("get deleted rows of %s: %w", t.Schema.Name, err)
+	}
+	return updated, deleted, nil
+}
+
+// createSinkKey 在目标表上建 (symbol, TimeCol) 唯一索引，供 Upsert 判断冲突
+func createSinkKey(dst Backend, t SinkTable) error {
+	query := fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %[1]s_sink_key ON %[1]s (symbol, %[2]s)", t.Schema.Name, t.TimeCol)
+	if _, err := dst.DB().Exec(query); err != nil {
+		return fmt.Errorf("failed to create unique index on %s: %w", t.Schema.Name, err)
+	}
+	return nil
+}
+
+// sinkVersion 目标库中 table 已同步到的批次，ok 为 false 表示还没有同步记录
+func sinkVersion(dst Backend, table string) (version int64, ok bool, err error) {
+	if err := dst.CreateTable(SinkStateSchema); err != nil {
+		return 0, false, err
+	}
+	query := fmt.Sprintf("SELECT version FROM %s WHERE table_name = '%s'", SinkStateSchema.Name, table)
+	err = dst.DB().QueryRow(query).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to query sink state of %s: %w", table, err)
+	}
+	return version, true, nil
+}
+
+func saveSinkVersion(ctx context.Context, dst Backend, table string, version int64) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE table_name = '%s'", SinkStateSchema.Name, table)
+	if _, err := dst.DB().ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to clear sink state of %s: %w", table, err)
+	}
+	_, err := dst.Append(ctx, SinkStateSchema, func(emit func(row ...any) error) error {
+		return emit(table, version, time.Now())
+	})
+	return err
+}
+
+// syncReplace 整表写入目标库的暂存表后替换
+func syncReplace(ctx context.Context, db *sql.DB, dst Backend, schema TableSchema) (SyncStats, error) {
+	st := SyncStats{Table: schema.Name, Replaced: true}
+	stage := TableSchema{Name: schema.Name + "_stage", Columns: schema.Columns, Keys: schema.Keys}
+	if err := dst.DropTable(stage); err != nil {
+		return st, err
+	}
+	if err := dst.CreateTable(stage); err != nil {
+		return st, err
+	}
+
+	names := schemaColumnNames(schema)
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(names, ", "), schema.Name)
+
+	var err error
+	st.Rows, err = dst.Append(ctx, stage, querySource(db, query, len(names)))
+	if err != nil {
+		return st, err
+	}
+	return st, dst.SwapTable(ctx, stage.Name, schema.Name)
+}
+
+// querySource 把查询结果包装为 RowSource
+func querySource(db *sql.DB, query string, n int) RowSource {
+	return func(emit func(row ...any) error) error {
+		rows, err := db.Query(query)
+		if err != nil {
+			return fmt.Errorf("failed to query source rows: %w", err)
+		}
+		defer rows.Close()
+
+		values := make([]any, n)
+		ptrs := make([]any, n)
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		for rows.Next() {
+			if err := rows.Scan(ptrs...); err != nil {
+				return fmt.Errorf("failed to scan source row: %w", err)
+			}
+			if err := emit(values...); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	}
+}
@@ -0,0 +1,99 @@
+-- Generated by `tdx2db dump-schema --output database/sql`, do not edit
+-- Dialect: DuckDB
+
+-- v_security_name_daily: 每个交易日有效的名称和 ST 状态
+CREATE OR REPLACE VIEW v_security_name_daily AS
+SELECT
+    s.symbol,
+    s.date,
+    h.name,
+    COALESCE(h.st_flag, '') AS st_flag,
+    COALESCE(h.st_flag, '') <> '' AS is_st
+FROM raw_stocks_daily s
+LEFT JOIN raw_security_name_history h
+    ON s.symbol = h.symbol
+    AND s.date >= h.valid_from
+    AND (h.valid_to IS NULL OR s.date <= h.valid_to);
+
+-- v_stitched_daily: 按经济实体拼接
+CREATE OR REPLACE VIEW v_stitched_daily AS
+        SELECT
+            COALESCE(m.entity, s.symbol) AS entity,
+            s.symbol,
+            s.date,
+            ROUND(s.open  / COALESCE(m.ratio, 1), 4) AS open,
+            ROUND(s.high  / COALESCE(m.ratio, 1), 4) AS high,
+            ROUND(s.low   / COALESCE(m.ratio, 1), 4) AS low,
+            ROUND(s.close / COALESCE(m.ratio, 1), 4) AS close,
+            s.amount,
+            CAST(ROUND(s.volume * COALESCE(m.ratio, 1)) AS BIGINT) AS volume
+        FROM raw_stocks_daily s
+
+LEFT JOIN raw_symbol_map m ON m.symbol = s.symbol
+WHERE m.symbol IS NULL
+   OR (s.date >= COALESCE(m.valid_from, DATE '1900-01-01')
+       AND s.date <= COALESCE(m.valid_to, DATE '9999-12-31'));
+
+-- v_stitched_factor: 按经济实体拼接
+CREATE OR REPLACE VIEW v_stitched_factor AS
+        WITH seg AS (
+            SELECT
+                COALESCE(m.entity, f.symbol) AS entity,
+                f.symbol,
+                f.date,
+                f.close / COALESCE(m.ratio, 1) AS close,
+                f.pre_close / COALESCE(m.ratio, 1) AS pre_close,
+                f.hfq_factor
+            FROM raw_adjust_factor f
+
+LEFT JOIN raw_symbol_map m ON m.symbol = f.symbol
+WHERE m.symbol IS NULL
+   OR (f.date >= COALESCE(m.valid_from, DATE '1900-01-01')
+       AND f.date <= COALESCE(m.valid_to, DATE '9999-12-31'))
+        ),
+        steps AS (
+            SELECT
+                *,
+                LAG(symbol) OVER w AS prev_symbol,
+                LAG(close) OVER w AS prev_close,
+                CASE
+                    WHEN LAG(symbol) OVER w = symbol AND LAG(hfq_factor) OVER w <> 0
+                    THEN hfq_factor / LAG(hfq_factor) OVER w
+                    ELSE 1.0
+                END AS step
+            FROM seg
+            WINDOW w AS (PARTITION BY entity ORDER BY date)
+        ),
+        hfq AS (
+            SELECT
+                *,
+                PRODUCT(step) OVER (PARTITION BY entity ORDER BY date ROWS UNBOUNDED PRECEDING) AS stitched_hfq
+            FROM steps
+        )
+        SELECT
+            entity,
+            symbol,
+            date,
+            ROUND(close, 4) AS close,
+            ROUND(CASE WHEN prev_symbol IS NOT NULL AND prev_symbol <> symbol THEN prev_close ELSE pre_close END, 4) AS pre_close,
+            ROUND(stitched_hfq / LAST_VALUE(stitched_hfq) OVER (PARTITION BY entity ORDER BY date ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING), 4) AS qfq_factor,
+            ROUND(stitched_hfq, 4) AS hfq_factor
+        FROM hfq;
+
+-- v_stitched_caiwu: 按经济实体拼接
+CREATE OR REPLACE VIEW v_stitched_caiwu AS
+SELECT
+    COALESCE(m.entity_code, c.code) AS entity_code,
+    c.*
+FROM raw_caiwu c
+LEFT JOIN (
+    SELECT DISTINCT SUBSTR(symbol, 3) AS code, SUBSTR(entity, 3) AS entity_code, valid_from, valid_to
+    FROM raw_symbol_map
+    WHERE symbol <> entity
+) m ON m.code = c.code
+   AND c.report_date >= COALESCE(m.valid_from, DATE '1900-01-01')
+   AND c.report_date <= COALESCE(m.valid_to, DATE '9999-12-31')
+QUALIFY ROW_NUMBER() OVER (
+    PARTITION BY COALESCE(m.entity_code, c.code), c.report_date
+    ORDER BY c.code = COALESCE(m.entity_code, c.code) DESC
+) = 1;
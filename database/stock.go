@@ -27,23 +27,35 @@ var StocksSchema = TableSchema{
 var QfqViewName = "v_qfq_stocks"
 var HfqViewName = "v_hfq_stocks"
 
-func CreateQfqView(db *sql.DB) error {
-	query := fmt.Sprintf(`
+func qfqViewSQL() string {
+	return fmt.Sprintf(`
 	CREATE OR REPLACE VIEW %s AS
 	SELECT
 		s.symbol,
+		sec.name,
+		sec.subtype,
 		s.date,
-		s.volume,
+		CAST(ROUND(s.volume / NULLIF(f.qfq_factor, 0)) AS BIGINT) AS volume,
 		s.amount,
-		ROUND(s.open  * f.qfq_factor, 2) AS open,
-		ROUND(s.high  * f.qfq_factor, 2) AS high,
-		ROUND(s.low   * f.qfq_factor, 2) AS low,
-		ROUND(s.close * f.qfq_factor, 2) AS close,
-		t.turnover
+		ROUND(s.open  * f.qfq_factor, 4) AS open,
+		ROUND(s.high  * f.qfq_factor, 4) AS high,
+		ROUND(s.low   * f.qfq_factor, 4) AS low,
+		ROUND(s.close * f.qfq_factor, 4) AS close,
+		ROUND(s.volume / (c.float_shares * 10000), 4) AS turnover
 	FROM %s s
 	JOIN %s f ON s.symbol = f.symbol AND s.date = f.date
-	LEFT JOIN %s t ON s.symbol = t.symbol AND s.date = t.date;
-	`, QfqViewName, StocksSchema.Name, FactorSchema.Name, TurnoverViewName)
+	LEFT JOIN %s c ON s.symbol = c.symbol AND s.date = c.date
+	LEFT JOIN %s sec ON s.symbol = sec.symbol;
+	`, QfqViewName, StocksSchema.Name, FactorSchema.Name, CapitalSchema.Name, SecuritySchema.Name)
+}
+
+func CreateQfqView(db *sql.DB) error {
+	// base 未运行时 raw_security 为空表，name、subtype 为 NULL
+	if err := CreateTable(db, SecuritySchema); err != nil {
+		return err
+	}
+
+	query := qfqViewSQL()
 
 	_, err := db.Exec(query)
 	if err != nil {
@@ -52,23 +64,35 @@ func CreateQfqView(db *sql.DB) error {
 	return nil
 }
 
-func CreateHfqView(db *sql.DB) error {
-	query := fmt.Sprintf(`
+func hfqViewSQL() string {
+	return fmt.Sprintf(`
 	CREATE OR REPLACE VIEW %s AS
 	SELECT
 		s.symbol,
+		sec.name,
+		sec.subtype,
 		s.date,
-		s.volume,
+		CAST(ROUND(s.volume / NULLIF(f.hfq_factor, 0)) AS BIGINT) AS volume,
 		s.amount,
-		ROUND(s.open  * f.hfq_factor, 2) AS open,
-		ROUND(s.high  * f.hfq_factor, 2) AS high,
-		ROUND(s.low   * f.hfq_factor, 2) AS low,
-		ROUND(s.close * f.hfq_factor, 2) AS close,
-		t.turnover
+		ROUND(s.open  * f.hfq_factor, 4) AS open,
+		ROUND(s.high  * f.hfq_factor, 4) AS high,
+		ROUND(s.low   * f.hfq_factor, 4) AS low,
+		ROUND(s.close * f.hfq_factor, 4) AS close,
+		ROUND(s.volume / (c.float_shares * 10000), 4) AS turnover
 	FROM %s s
 	JOIN %s f ON s.symbol = f.symbol AND s.date = f.date
-	LEFT JOIN %s t ON s.symbol = t.symbol AND s.date = t.date;
-	`, HfqViewName, StocksSchema.Name, FactorSchema.Name, TurnoverViewName)
+	LEFT JOIN %s c ON s.symbol = c.symbol AND s.date = c.date
+	LEFT JOIN %s sec ON s.symbol = sec.symbol;
+	`, HfqViewName, StocksSchema.Name, FactorSchema.Name, CapitalSchema.Name, SecuritySchema.Name)
+}
+
+func CreateHfqView(db *sql.DB) error {
+	// base 未运行时 raw_security 为空表，name、subtype 为 NULL
+	if err := CreateTable(db, SecuritySchema); err != nil {
+		return err
+	}
+
+	query := hfqViewSQL()
 
 	_, err := db.Exec(query)
 	if err != nil {
@@ -0,0 +1,366 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+)
+
+// 代码变更事件，来自本地 CSV：old_symbol,new_symbol,date[,ratio][,note]
+var SymbolChangeSchema = TableSchema{
+	Name: "raw_symbol_change",
+	Columns: []string{
+		"old_symbol VARCHAR",
+		"new_symbol VARCHAR",
+		"date DATE /*新代码生效日*/",
+		"ratio DOUBLE /*每股旧股换得的新股数，代码变更为 1*/",
+		"note VARCHAR",
+	},
+	Keys: []string{"PRIMARY KEY (old_symbol)"},
+}
+
+// 代码到经济实体的映射，entity 为变更链上最新的代码
+var SymbolMapSchema = TableSchema{
+	Name: "raw_symbol_map",
+	Columns: []string{
+		"symbol VARCHAR",
+		"entity VARCHAR",
+		"valid_from DATE /*为 NULL 表示不限*/",
+		"valid_to DATE /*为 NULL 表示至今有效*/",
+		"ratio DOUBLE /*每股折合实体股数，价格除以 ratio、成交量乘以 ratio 得到实体口径*/",
+		"source VARCHAR /*csv delist tnf*/",
+	},
+	Keys: []string{"PRIMARY KEY (symbol)"},
+}
+
+var StitchedDailyViewName = "v_stitched_daily"
+var StitchedFactorViewName = "v_stitched_factor"
+var StitchedCaiwuViewName = "v_stitched_caiwu"
+
+// ImportSymbolChangeCsv 读取代码变更 CSV 并替换 raw_symbol_change，
+// 首行为表头，ratio 为空时按 1 处理
+func ImportSymbolChangeCsv(db *sql.DB, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, col := range []string{"old_symbol", "new_symbol", "date"} {
+		if _, ok := index[col]; !ok {
+			return 0, fmt.Errorf("missing column %s in %s", col, path)
+		}
+	}
+	field := func(rec []string, col string) string {
+		i, ok := index[col]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	tmpFile, err := os.CreateTemp("", "tdx-symbol-change.csv")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	writer := csv.NewWriter(tmpFile)
+	if err := writer.Write([]string{"old_symbol", "new_symbol", "date", "ratio", "note"}); err != nil {
+		return 0, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	count := 0
+	seen := make(map[string]bool)
+	for line := 2; ; line++ {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s line %d: %w", path, line, err)
+		}
+
+		oldSymbol := strings.ToLower(field(rec, "old_symbol"))
+		newSymbol := strings.ToLower(field(rec, "new_symbol"))
+		if oldSymbol == "" || newSymbol == "" || oldSymbol == newSymbol {
+			return 0, fmt.Errorf("invalid symbols at %s line %d", path, line)
+		}
+		if seen[oldSymbol] {
+			return 0, fmt.Errorf("duplicate old_symbol %s at %s line %d", oldSymbol, path, line)
+		}
+		seen[oldSymbol] = true
+
+		date, err := time.Parse("2006-01-02", field(rec, "date"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid date at %s line %d: %w", path, line, err)
+		}
+
+		ratio := 1.0
+		if s := field(rec, "ratio"); s != "" {
+			ratio, err = strconv.ParseFloat(s, 64)
+			if err != nil || ratio <= 0 {
+				return 0, fmt.Errorf("invalid ratio at %s line %d", path, line)
+			}
+		}
+
+		row := []string{
+			oldSymbol,
+			newSymbol,
+			date.Format("2006-01-02"),
+			strconv.FormatFloat(ratio, 'f', -1, 64),
+			field(rec, "note"),
+		}
+		if err := writer.Write(row); err != nil {
+			return 0, fmt.Errorf("failed to write CSV row: %w", err)
+		}
+		count++
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return 0, fmt.Errorf("failed to flush CSV: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close temp CSV: %w", err)
+	}
+
+	if err := DropTable(db, SymbolChangeSchema); err != nil {
+		return 0, fmt.Errorf("failed to drop table: %w", err)
+	}
+	if err := CreateTable(db, SymbolChangeSchema); err != nil {
+		return 0, fmt.Errorf("failed to create table: %w", err)
+	}
+	if err := ImportCSV(db, SymbolChangeSchema, tmpFile.Name()); err != nil {
+		return 0, fmt.Errorf("failed to import CSV: %w", err)
+	}
+
+	return count, nil
+}
+
+// RebuildSymbolMap 由代码变更、退市名单 (raw_delist) 和 TNF 证券信息 (raw_security) 重建 raw_symbol_map：
+//   - 变更链上的旧代码映射到链尾的新代码，有效期截至变更日前一天，ratio 为沿链累乘的换股比例
+//   - 新代码自变更日起有效
+//   - 退市代码的有效期为上市日至退市日，TNF 中的代码至今有效
+//
+// 不存在的来源表跳过
+func RebuildSymbolMap(db *sql.DB) error {
+	if err := CreateTable(db, SymbolChangeSchema); err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	symbols := []string{
+		fmt.Sprintf("SELECT old_symbol AS symbol FROM %s", SymbolChangeSchema.Name),
+		fmt.Sprintf("SELECT new_symbol FROM %s", SymbolChangeSchema.Name),
+	}
+	delist := "SELECT NULL::VARCHAR AS symbol, NULL::DATE AS inlist, NULL::DATE AS delist WHERE FALSE"
+
+	columns, err := tableColumns(db, DelistSchema.Name)
+	if err != nil {
+		return err
+	}
+	if len(columns) > 0 {
+		delist = fmt.Sprintf(`
+			SELECT mkt || code AS symbol, MIN(inlist) AS inlist, MAX(delist) AS delist
+			FROM %s WHERE code IS NOT NULL AND mkt IS NOT NULL GROUP BY 1`, DelistSchema.Name)
+		symbols = append(symbols, "SELECT symbol FROM delist")
+	}
+
+	columns, err = tableColumns(db, SecuritySchema.Name)
+	if err != nil {
+		return err
+	}
+	if len(columns) > 0 {
+		symbols = append(symbols, fmt.Sprintf("SELECT symbol FROM %s", SecuritySchema.Name))
+	}
+
+	query := fmt.Sprintf(`
+		CREATE OR REPLACE TABLE %[1]s AS
+		WITH RECURSIVE chain(symbol, entity, ratio, depth) AS (
+			SELECT old_symbol, new_symbol, ratio, 1 FROM %[2]s
+			UNION ALL
+			SELECT c.symbol, ch.new_symbol, c.ratio * ch.ratio, c.depth + 1
+			FROM chain c
+			JOIN %[2]s ch ON ch.old_symbol = c.entity
+			WHERE c.depth < 32
+		),
+		resolved AS (
+			SELECT symbol, ARG_MAX(entity, depth) AS entity, ARG_MAX(ratio, depth) AS ratio
+			FROM chain
+			GROUP BY symbol
+		),
+		delist AS (%[3]s),
+		syms AS (%[4]s)
+		SELECT
+			s.symbol,
+			COALESCE(r.entity, s.symbol) AS entity,
+			COALESCE(nc.date, d.inlist) AS valid_from,
+			COALESCE(oc.date - INTERVAL 1 DAY, d.delist)::DATE AS valid_to,
+			COALESCE(r.ratio, 1.0) AS ratio,
+			CASE
+				WHEN oc.old_symbol IS NOT NULL OR nc.new_symbol IS NOT NULL THEN 'csv'
+				WHEN d.symbol IS NOT NULL THEN 'delist'
+				ELSE 'tnf'
+			END AS source
+		FROM (SELECT DISTINCT symbol FROM syms WHERE symbol IS NOT NULL) s
+		LEFT JOIN resolved r ON r.symbol = s.symbol
+		LEFT JOIN %[2]s oc ON oc.old_symbol = s.symbol
+		LEFT JOIN (SELECT new_symbol, MIN(date) AS date FROM %[2]s GROUP BY new_symbol) nc ON nc.new_symbol = s.symbol
+		LEFT JOIN delist d ON d.symbol = s.symbol
+	`, SymbolMapSchema.Name, SymbolChangeSchema.Name, delist, strings.Join(symbols, " UNION ALL "))
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to rebuild symbol map: %w", err)
+	}
+	return nil
+}
+
+// symbolMapJoin 按代码和有效期关联 raw_symbol_map，不在映射中的代码自成一个实体
+func symbolMapJoin(symbolExpr, dateExpr string) string {
+	return fmt.Sprintf(`
+		LEFT JOIN %[1]s m ON m.symbol = %[2]s
+		WHERE m.symbol IS NULL
+		   OR (%[3]s >= COALESCE(m.valid_from, DATE '1900-01-01')
+		       AND %[3]s <= COALESCE(m.valid_to, DATE '9999-12-31'))`,
+		SymbolMapSchema.Name, symbolExpr, dateExpr)
+}
+
+// stitchedView 拼接视图，source 不存在时不创建
+type stitchedView struct {
+	name   string
+	source string
+	query  string
+}
+
+func (v stitchedView) ddl() string {
+	return fmt.Sprintf("CREATE OR REPLACE VIEW %s AS\n%s;", v.name, dedentSQL(v.query))
+}
+
+func stitchedViews() []stitchedView {
+	return []stitchedView{
+		{
+			name:   StitchedDailyViewName,
+			source: StocksSchema.Name,
+			query: fmt.Sprintf(`
+				SELECT
+					COALESCE(m.entity, s.symbol) AS entity,
+					s.symbol,
+					s.date,
+					ROUND(s.open  / COALESCE(m.ratio, 1), 4) AS open,
+					ROUND(s.high  / COALESCE(m.ratio, 1), 4) AS high,
+					ROUND(s.low   / COALESCE(m.ratio, 1), 4) AS low,
+					ROUND(s.close / COALESCE(m.ratio, 1), 4) AS close,
+					s.amount,
+					CAST(ROUND(s.volume * COALESCE(m.ratio, 1)) AS BIGINT) AS volume
+				FROM %s s
+				%s`, StocksSchema.Name, symbolMapJoin("s.symbol", "s.date")),
+		},
+		{
+			// 拼接处没有除权，后复权因子在每段内沿用原因子的日变化，跨段处变化为 1
+			name:   StitchedFactorViewName,
+			source: FactorSchema.Name,
+			query: fmt.Sprintf(`
+				WITH seg AS (
+					SELECT
+						COALESCE(m.entity, f.symbol) AS entity,
+						f.symbol,
+						f.date,
+						f.close / COALESCE(m.ratio, 1) AS close,
+						f.pre_close / COALESCE(m.ratio, 1) AS pre_close,
+						f.hfq_factor
+					FROM %s f
+					%s
+				),
+				steps AS (
+					SELECT
+						*,
+						LAG(symbol) OVER w AS prev_symbol,
+						LAG(close) OVER w AS prev_close,
+						CASE
+							WHEN LAG(symbol) OVER w = symbol AND LAG(hfq_factor) OVER w <> 0
+							THEN hfq_factor / LAG(hfq_factor) OVER w
+							ELSE 1.0
+						END AS step
+					FROM seg
+					WINDOW w AS (PARTITION BY entity ORDER BY date)
+				),
+				hfq AS (
+					SELECT
+						*,
+						PRODUCT(step) OVER (PARTITION BY entity ORDER BY date ROWS UNBOUNDED PRECEDING) AS stitched_hfq
+					FROM steps
+				)
+				SELECT
+					entity,
+					symbol,
+					date,
+					ROUND(close, 4) AS close,
+					ROUND(CASE WHEN prev_symbol IS NOT NULL AND prev_symbol <> symbol THEN prev_close ELSE pre_close END, 4) AS pre_close,
+					ROUND(stitched_hfq / LAST_VALUE(stitched_hfq) OVER (PARTITION BY entity ORDER BY date ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING), 4) AS qfq_factor,
+					ROUND(stitched_hfq, 4) AS hfq_factor
+				FROM hfq`, FactorSchema.Name, symbolMapJoin("f.symbol", "f.date")),
+		},
+		{
+			// 同一实体同一报告期有多条时保留实体自身代码的记录
+			name:   StitchedCaiwuViewName,
+			source: CaiwuSchema.Name,
+			query: fmt.Sprintf(`
+				SELECT
+					COALESCE(m.entity_code, c.code) AS entity_code,
+					c.*
+				FROM %[1]s c
+				LEFT JOIN (
+					SELECT DISTINCT SUBSTR(symbol, 3) AS code, SUBSTR(entity, 3) AS entity_code, valid_from, valid_to
+					FROM %[2]s
+					WHERE symbol <> entity
+				) m ON m.code = c.code
+				   AND c.report_date >= COALESCE(m.valid_from, DATE '1900-01-01')
+				   AND c.report_date <= COALESCE(m.valid_to, DATE '9999-12-31')
+				QUALIFY ROW_NUMBER() OVER (
+					PARTITION BY COALESCE(m.entity_code, c.code), c.report_date
+					ORDER BY c.code = COALESCE(m.entity_code, c.code) DESC
+				) = 1`, CaiwuSchema.Name, SymbolMapSchema.Name),
+		},
+	}
+}
+
+// CreateStitchedViews 创建按经济实体拼接的日线、复权因子和财务视图，
+// 价格和成交量按 ratio 折算为实体口径；来源表不存在时跳过对应视图
+func CreateStitchedViews(db *sql.DB) error {
+	if err := CreateTable(db, SymbolMapSchema); err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	for _, v := range stitchedViews() {
+		columns, err := tableColumns(db, v.source)
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		if _, err := db.Exec(v.ddl()); err != nil {
+			return fmt.Errorf("failed to create or replace view %s: %w", v.name, err)
+		}
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ go 1.25.3
 require (
 	github.com/LindsayBradford/go-dbf v1.0.0-aplha.4
 	github.com/duckdb/duckdb-go/v2 v2.5.0
+	github.com/lib/pq v1.10.9
+	github.com/mattn/go-sqlite3 v1.14.52
 	github.com/spf13/cobra v1.10.1
 	golang.org/x/text v0.28.0
 )
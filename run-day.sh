@@ -25,17 +25,16 @@ cd ../../..
 ./tdx2db workday  --dbpath tdx.db --wdyear $Year --wdpath ./datatool/vipdoc/exceptday 
 
 mkdir -p ./datatool/vipdoc/refmhq
-cd ./datatool/vipdoc/refmhq && wget https://www.tdx.com.cn/products/data/data/g4day/$Year$i.zip && unzip $Year$i.zip && rm -rf $Year$i.zip
+cd ./datatool/vipdoc/refmhq && wget https://www.tdx.com.cn/products/data/data/g4day/$Year$i.zip
 cd ../../..
-docker run -it -v $CUR/datatool:/datatool -w /datatool alpine:3.19 ./datatool day create $Year$i
+./tdx2db  cron --dbpath tdx.db --dayzip ./datatool/vipdoc/refmhq/$Year$i.zip
 rm -rf ./datatool/vipdoc/refmhq/*
-./tdx2db  cron --dbpath tdx.db
 
 #mkdir -p ./datatool/vipdoc/newdatetick
-#cd ./datatool/vipdoc/newdatetick && wget https://www.tdx.com.cn/products/data/data/g4tic/$Year$i.zip && unzip $Year$i.zip && rm -rf $Year$i.zip
+#cd ./datatool/vipdoc/newdatetick && wget https://www.tdx.com.cn/products/data/data/g4tic/$Year$i.zip
 #cd ../../..
-#docker run -it -v $CUR/datatool:/datatool -w /datatool alpine:3.19 ./datatool tick create $Year$i
-#docker run -it -v $CUR/datatool:/datatool -w /datatool alpine:3.19 ./datatool min create $Year$i
+#./tdx2db cron --dbpath tdx.db --ticzip ./datatool/vipdoc/newdatetick/$Year$i.zip
+#rm -rf ./datatool/vipdoc/newdatetick/*
 
 ./tdx2db cw --cwpath datatool/vipdoc/tdxfin --cwdl true --dbpath tdx.db
 ./tdx2db base --basepath datatool/vipdoc/base --dbpath tdx.db 
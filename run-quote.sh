@@ -16,9 +16,9 @@ if [ $SYSTEM = "Linux" ] ; then
 ./calc --c conf/config.yml daysplit --in /mnt/e/data/day/$Year/ --out /mnt/e/data/split/ --day $Year$i
 elif [ $SYSTEM = "Darwin" ] ; then
 mkdir -p ./datatool/vipdoc/refmhq
-cd ./datatool/vipdoc/refmhq && wget https://www.tdx.com.cn/products/data/data/g4day/$Year$i.zip && unzip $Year$i.zip && rm -rf $Year$i.zip
+cd ./datatool/vipdoc/refmhq && wget https://www.tdx.com.cn/products/data/data/g4day/$Year$i.zip
 cd ../../..
-docker run -it -v $CUR/datatool:/datatool -w /datatool alpine:3.19 ./datatool day create $Year$i
+./tdx2db  cron --dbpath tdx.db --dayzip ./datatool/vipdoc/refmhq/$Year$i.zip
 rm -rf ./datatool/vipdoc/refmhq/*
 
 
@@ -267,3 +267,129 @@ func ReadDelist(path string) ([]*BlockData, error) {
 	//fmt.Printf("len:%d\n", len(res))
 	return res, nil
 }
+
+// ---------- 文件生成 ----------
+
+const (
+	blockHeaderSize = 84
+	blockNameSize   = 9
+	blockCodeSlots  = 400 //每个板块最多 400 只证券
+	blockCodeSize   = 7   //6 位代码 + 0x00
+	blockRecordSize = blockNameSize + 2 + 2 + blockCodeSlots*blockCodeSize
+)
+
+// UTF8 转 GBK
+func utf8ToGBK(s string) ([]byte, error) {
+	res, _, err := transform.Bytes(simplifiedchinese.GBK.NewEncoder(), []byte(s))
+	return res, err
+}
+
+func putCString(buf []byte, s string) error {
+	b, err := utf8ToGBK(s)
+	if err != nil {
+		return fmt.Errorf("encode %q to GBK: %w", s, err)
+	}
+	if len(b) >= len(buf) {
+		return fmt.Errorf("%q exceeds %d bytes in GBK", s, len(buf)-1)
+	}
+	copy(buf, b)
+	return nil
+}
+
+func putBlockIndex(buf []byte, name string, length, offset uint32) error {
+	if err := putCString(buf[0:64], name); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(buf[72:76], length)
+	binary.LittleEndian.PutUint32(buf[76:80], offset)
+	return nil
+}
+
+// encodeBlockData 编码单个板块，定长 blockRecordSize 字节，代码为不带市场的 6 位代码
+func encodeBlockData(buf []byte, b *BlockData) error {
+	if len(b.Codes) > blockCodeSlots {
+		return fmt.Errorf("block %s has %d codes, max %d", b.Name, len(b.Codes), blockCodeSlots)
+	}
+	if err := putCString(buf[0:blockNameSize], b.Name); err != nil {
+		return fmt.Errorf("block name: %w", err)
+	}
+	binary.LittleEndian.PutUint16(buf[9:11], uint16(len(b.Codes)))
+	binary.LittleEndian.PutUint16(buf[11:13], b.Level)
+
+	offset := blockNameSize + 4
+	for _, code := range b.Codes {
+		if err := putCString(buf[offset:offset+blockCodeSize], code); err != nil {
+			return fmt.Errorf("block %s code: %w", b.Name, err)
+		}
+		offset += blockCodeSize
+	}
+	return nil
+}
+
+// WriteBlock 生成与 ReadBlock 相同布局的 block*.dat 文件：文件头、Block/Val 两个索引和定长板块数据
+func WriteBlock(path string, blocks []*BlockData) error {
+	if len(blocks) == 0 || len(blocks) > 0xFFFF {
+		return fmt.Errorf("invalid block count %d", len(blocks))
+	}
+
+	indexOffset := uint32(blockHeaderSize)
+	dataOffset := indexOffset + 2*indexSize
+	valLength := uint32(len(blocks) * blockRecordSize)
+
+	buf := make([]byte, int(dataOffset)+2+int(valLength))
+	if err := putCString(buf[0:64], "tdx2db"); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(buf[64:68], indexOffset)
+	binary.LittleEndian.PutUint32(buf[68:72], dataOffset)
+
+	idx := buf[indexOffset:dataOffset]
+	if err := putBlockIndex(idx[0:indexSize], "Block", 2, 0); err != nil {
+		return err
+	}
+	if err := putBlockIndex(idx[indexSize:2*indexSize], "Val", valLength, 2); err != nil {
+		return err
+	}
+
+	data := buf[dataOffset:]
+	binary.LittleEndian.PutUint16(data[0:2], uint16(len(blocks)))
+	for i, b := range blocks {
+		start := 2 + i*blockRecordSize
+		if err := encodeBlockData(data[start:start+blockRecordSize], b); err != nil {
+			return err
+		}
+	}
+
+	if err := os.WriteFile(path, buf, 0644); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
+
+// WriteBlk 生成客户端自定义板块 (T0002/blocknew/*.blk) 文件，每行为市场位 + 6 位代码
+// 市场位：0 深圳 1 上海 2 北京
+func WriteBlk(path string, symbols []string) error {
+	var sb strings.Builder
+	for _, symbol := range symbols {
+		if len(symbol) != 8 {
+			return fmt.Errorf("invalid symbol %q", symbol)
+		}
+		switch symbol[:2] {
+		case "sz":
+			sb.WriteString("0")
+		case "sh":
+			sb.WriteString("1")
+		case "bj":
+			sb.WriteString("2")
+		default:
+			return fmt.Errorf("invalid market in symbol %q", symbol)
+		}
+		sb.WriteString(symbol[2:])
+		sb.WriteString("\r\n")
+	}
+
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
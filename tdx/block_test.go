@@ -0,0 +1,67 @@
+package tdx
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteBlockRoundTrip(t *testing.T) {
+	codes := make([]string, blockCodeSlots)
+	for i := range codes {
+		codes[i] = "600000"
+	}
+	blocks := []*BlockData{
+		{Name: "银行", Count: 3, Level: 1, Codes: []string{"600000", "000001", "920001"}},
+		{Name: "tdx2db", Count: 0, Level: 0, Codes: []string{}},
+		{Name: "满仓", Count: blockCodeSlots, Level: 2, Codes: codes},
+	}
+	path := filepath.Join(t.TempDir(), "block_zs.dat")
+	if err := WriteBlock(path, blocks); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadBlock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, blocks) {
+		for i := range got {
+			t.Logf("block %d: %+v", i, *got[i])
+		}
+		t.Fatalf("ReadBlock returned %d blocks, want %+v", len(got), blocks)
+	}
+
+	// 超出定长字段的板块名和代码数量
+	for _, b := range []*BlockData{
+		{Name: "超过九字节的板块", Codes: []string{"600000"}},
+		{Name: "too many", Codes: append(codes, "600000")},
+	} {
+		if err := WriteBlock(path, []*BlockData{b}); err == nil {
+			t.Errorf("block %q with %d codes: want error", b.Name, len(b.Codes))
+		}
+	}
+}
+
+func TestWriteBlk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zxg.blk")
+	if err := WriteBlk(path, []string{"sz000001", "sh600000", "bj920001"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "0000001\r\n1600000\r\n2920001\r\n"
+	if string(data) != want {
+		t.Fatalf("blk = %q, want %q", data, want)
+	}
+
+	for _, symbol := range []string{"hk00700", "us000001", "600000"} {
+		if err := WriteBlk(path, []string{symbol}); err == nil || !strings.Contains(err.Error(), symbol) {
+			t.Errorf("symbol %q: got %v, want error", symbol, err)
+		}
+	}
+}
@@ -18,6 +18,7 @@ type internalCombinedData struct {
 	Peigu       float64
 	Peigujia    float64
 	Songzhuangu float64
+	Suogu       float64
 }
 
 func CalculateFqFactor(stockData []model.StockData, xdxrData []model.XdxrData) ([]model.Factor, error) {
@@ -146,10 +147,12 @@ func calculatePreClose(stockData []model.StockData, xdxrData []model.XdxrData) (
 			data.Peigu = xdxr.Peigu
 			data.Peigujia = xdxr.Peigujia
 			data.Songzhuangu = xdxr.Songzhuangu
+			data.Suogu = xdxr.Suogu
 		} else {
 			dataMap[dateStr] = &internalCombinedData{
 				Date: xdxr.Date, Symbol: symbol, IsTradeDay: false,
 				Fenhong: xdxr.Fenhong, Peigu: xdxr.Peigu, Peigujia: xdxr.Peigujia, Songzhuangu: xdxr.Songzhuangu,
+				Suogu: xdxr.Suogu,
 			}
 		}
 	}
@@ -204,11 +207,15 @@ func calculatePreClose(stockData []model.StockData, xdxrData []model.XdxrData) (
 			// GBBQ 数据异常，但为了健壮性，我们认为价格不变，而不是返回错误中断整个流程
 			// return nil, fmt.Errorf("division by zero on date %v for symbol %s", currData.Date, currData.Symbol)
 			currData.PreClose = prevClose
-			continue
+		} else {
+			numerator := (prevClose*10 - currData.Fenhong) + (currData.Peigu * currData.Peigujia)
+			currData.PreClose = numerator / denominator
 		}
 
-		numerator := (prevClose*10 - currData.Fenhong) + (currData.Peigu * currData.Peigujia)
-		currData.PreClose = numerator / denominator
+		// 扩缩股：每股变为 Suogu 股，每股价格相应除以 Suogu
+		if currData.Suogu > 0 {
+			currData.PreClose /= currData.Suogu
+		}
 	}
 
 	return combined, nil
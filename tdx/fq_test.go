@@ -0,0 +1,107 @@
+package tdx
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jing2uo/tdx2db/model"
+)
+
+func fqDay(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// TestCalculateFqFactor 昨收 10 元，第二个交易日发生一次事件，检查当日前收盘价、
+// 首日前复权因子 (前收/昨收) 和末日后复权因子 (昨收/前收)
+func TestCalculateFqFactor(t *testing.T) {
+	cases := []struct {
+		name     string
+		eventDay string
+		xdxr     model.XdxrData
+		preClose float64
+		qfq, hfq float64
+	}{
+		{
+			name:     "cash dividend 10派5",
+			eventDay: "2024-01-08",
+			xdxr:     model.XdxrData{Fenhong: 5},
+			preClose: 9.5,
+			qfq:      0.95,
+			hfq:      10 / 9.5,
+		},
+		{
+			name:     "bonus shares 10送10",
+			eventDay: "2024-01-08",
+			xdxr:     model.XdxrData{Songzhuangu: 10},
+			preClose: 5,
+			qfq:      0.5,
+			hfq:      2,
+		},
+		{
+			name:     "rights issue 10配3 配股价 4 元",
+			eventDay: "2024-01-08",
+			xdxr:     model.XdxrData{Peigu: 3, Peigujia: 4},
+			preClose: 112.0 / 13,
+			qfq:      112.0 / 130,
+			hfq:      130.0 / 112,
+		},
+		{
+			name:     "consolidation 10缩1",
+			eventDay: "2024-01-08",
+			xdxr:     model.XdxrData{Suogu: 0.1},
+			preClose: 100,
+			qfq:      10,
+			hfq:      0.1,
+		},
+		{
+			// 非交易日的事件记在当日，之后第一个交易日的前收盘价沿用填充的收盘价
+			name:     "dividend on a weekend",
+			eventDay: "2024-01-06",
+			xdxr:     model.XdxrData{Fenhong: 5},
+			preClose: 10,
+			qfq:      0.95,
+			hfq:      10 / 9.5,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stocks := []model.StockData{
+				{Symbol: "sz000001", Close: 10, Date: fqDay("2024-01-05")},
+				{Symbol: "sz000001", Close: 9.8, Date: fqDay("2024-01-08")},
+			}
+			xdxr := c.xdxr
+			xdxr.Code = "000001"
+			xdxr.Date = fqDay(c.eventDay)
+
+			factors, err := CalculateFqFactor(stocks, []model.XdxrData{xdxr})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(factors) != 2 {
+				t.Fatalf("got %d factors, want 2", len(factors))
+			}
+			first, last := factors[0], factors[1]
+			for _, f := range []struct {
+				field     string
+				got, want float64
+			}{
+				{"first pre_close", first.PreClose, 10},
+				{"pre_close", last.PreClose, c.preClose},
+				{"first qfq", first.QfqFactor, c.qfq},
+				{"last qfq", last.QfqFactor, 1},
+				{"first hfq", first.HfqFactor, 1},
+				{"last hfq", last.HfqFactor, c.hfq},
+			} {
+				if math.Abs(f.got-f.want) > 1e-9 {
+					t.Errorf("%s = %v, want %v", f.field, f.got, f.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package tdx
+
+import (
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jing2uo/tdx2db/model"
+)
+
+// GbbqEvent 按类别解码后的股本变迁事件，c1..c4 的含义见 CategoryDetail
+type GbbqEvent interface {
+	EventCategory() int
+	EventCode() string
+	EventDate() time.Time
+}
+
+type gbbqEventBase struct {
+	Category int
+	Code     string
+	Date     time.Time
+}
+
+func (e gbbqEventBase) EventCategory() int   { return e.Category }
+func (e gbbqEventBase) EventCode() string    { return e.Code }
+func (e gbbqEventBase) EventDate() time.Time { return e.Date }
+
+// EventName 返回类别名称，如 除权除息
+func (e gbbqEventBase) EventName() string {
+	return Category[strconv.Itoa(e.Category)]
+}
+
+// DividendEvent 1 除权除息
+type DividendEvent struct {
+	gbbqEventBase
+	CashPer10   float64 //每10股派现(元)
+	RightsPrice float64 //配股价(元)
+	BonusPer10  float64 //每10股送转股(股)
+	RightsPer10 float64 //每10股配股(股)
+}
+
+// ShareChangeEvent 2 3 5 7 8 9 10 股本变动，单位万股
+type ShareChangeEvent struct {
+	gbbqEventBase
+	PrevFloat float64 //前流通盘
+	PrevTotal float64 //前总股本
+	Float     float64 //后流通盘
+	Total     float64 //后总股本
+}
+
+// IssuanceEvent 6 增发新股
+type IssuanceEvent struct {
+	gbbqEventBase
+	Price  float64 //增发价(元)
+	Shares float64 //增发数量(万股)
+}
+
+// ScaleEvent 11 扩缩股 12 非流通股缩股
+type ScaleEvent struct {
+	gbbqEventBase
+	Ratio float64 //比例
+}
+
+// WarrantEvent 13 送认购权证 14 送认沽权证
+type WarrantEvent struct {
+	gbbqEventBase
+	StrikePrice float64 //行权价(元)
+	Units       float64 //份数
+}
+
+// UnknownEvent 4 未知股本变动及未定义的类别，保留原始值
+type UnknownEvent struct {
+	gbbqEventBase
+	C1, C2, C3, C4 float64
+}
+
+// ShareChangeCategories 含前后流通盘、总股本的类别，7 股份回购也属于此类
+var ShareChangeCategories = []int{2, 3, 5, 7, 8, 9, 10}
+
+// ScaleCategories 11 扩缩股 12 非流通股缩股，比例在 c3
+var ScaleCategories = []int{11, 12}
+
+// XdxrCategories 影响复权因子的类别：1 除权除息和扩缩股
+var XdxrCategories = append([]int{1}, ScaleCategories...)
+
+// DecodeGbbqEvent 将通用的 c1..c4 记录解码为对应类别的事件
+func DecodeGbbqEvent(g model.GbbqData) GbbqEvent {
+	base := gbbqEventBase{Category: g.Category, Code: g.Code, Date: g.Date}
+	switch c := g.Category; {
+	case c == 1:
+		return DividendEvent{gbbqEventBase: base, CashPer10: g.C1, RightsPrice: g.C2, BonusPer10: g.C3, RightsPer10: g.C4}
+	case slices.Contains(ShareChangeCategories, c):
+		return ShareChangeEvent{gbbqEventBase: base, PrevFloat: g.C1, PrevTotal: g.C2, Float: g.C3, Total: g.C4}
+	case c == 6:
+		return IssuanceEvent{gbbqEventBase: base, Price: g.C2, Shares: g.C3}
+	case slices.Contains(ScaleCategories, c):
+		return ScaleEvent{gbbqEventBase: base, Ratio: g.C3}
+	case c == 13, c == 14:
+		return WarrantEvent{gbbqEventBase: base, StrikePrice: g.C1, Units: g.C3}
+	default:
+		return UnknownEvent{gbbqEventBase: base, C1: g.C1, C2: g.C2, C3: g.C3, C4: g.C4}
+	}
+}
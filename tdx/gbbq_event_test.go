@@ -0,0 +1,47 @@
+package tdx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jing2uo/tdx2db/model"
+)
+
+func TestDecodeGbbqEvent(t *testing.T) {
+	date := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	base := func(category int) gbbqEventBase {
+		return gbbqEventBase{Category: category, Code: "600000", Date: date}
+	}
+	shareChange := func(category int) GbbqEvent {
+		return ShareChangeEvent{gbbqEventBase: base(category), PrevFloat: 1, PrevTotal: 2, Float: 3, Total: 4}
+	}
+
+	// c1..c4 取不同的值，字段对应错误时会被发现
+	want := map[int]GbbqEvent{
+		1:  DividendEvent{gbbqEventBase: base(1), CashPer10: 1, RightsPrice: 2, BonusPer10: 3, RightsPer10: 4},
+		2:  shareChange(2),
+		3:  shareChange(3),
+		4:  UnknownEvent{gbbqEventBase: base(4), C1: 1, C2: 2, C3: 3, C4: 4},
+		5:  shareChange(5),
+		6:  IssuanceEvent{gbbqEventBase: base(6), Price: 2, Shares: 3},
+		7:  shareChange(7),
+		8:  shareChange(8),
+		9:  shareChange(9),
+		10: shareChange(10),
+		11: ScaleEvent{gbbqEventBase: base(11), Ratio: 3},
+		12: ScaleEvent{gbbqEventBase: base(12), Ratio: 3},
+		13: WarrantEvent{gbbqEventBase: base(13), StrikePrice: 1, Units: 3},
+		14: WarrantEvent{gbbqEventBase: base(14), StrikePrice: 1, Units: 3},
+		15: UnknownEvent{gbbqEventBase: base(15), C1: 1, C2: 2, C3: 3, C4: 4},
+	}
+	for category, w := range want {
+		got := DecodeGbbqEvent(model.GbbqData{Category: category, Code: "600000", Date: date, C1: 1, C2: 2, C3: 3, C4: 4})
+		if got != w {
+			t.Errorf("category %d: got %#v, want %#v", category, got, w)
+		}
+	}
+
+	if got := (DividendEvent{gbbqEventBase: base(1)}).EventName(); got != "除权除息" {
+		t.Errorf("EventName = %q", got)
+	}
+}
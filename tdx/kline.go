@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -108,7 +109,7 @@ func StreamDayFiles(filePath string, validPrefixes []string, handle func(DayKlin
 				producerWg.Done()
 			}()
 
-			if err := scanRecords(filename, ".day", func(recordBytes []byte, symbol string) {
+			if err := scanRecords(filename, func(recordBytes []byte, symbol string) {
 				record, err := processDayRecordValue(recordBytes, symbol)
 				if err != nil {
 					rowChan <- dayRowData{Err: fmt.Errorf("failed to process record in %s: %w", filename, err)}
@@ -131,16 +132,16 @@ func StreamDayFiles(filePath string, validPrefixes []string, handle func(DayKlin
 	return nil
 }
 
-// StreamMinFiles 将通达信 .01 或 .5 文件直接解析为结构化数据，并通过回调逐行消费。
+// StreamMinFiles 将通达信 .01/.5 (整数价格) 或 .lc1/.lc5 (浮点价格) 文件直接解析为结构化数据，并通过回调逐行消费。
 // 注意：回调在单个 goroutine 内串行调用，适合用 DuckDB Appender 等非并发写入方式。
-func StreamMinFiles(filePath string, validPrefixes []string, suffix string, handle func(MinKlineRecord) error) error {
-	switch suffix {
-	case ".01", ".5":
-	default:
-		return fmt.Errorf("unsupported file suffix: '%s'. Supported are .01, .5", suffix)
+func StreamMinFiles(filePath string, validPrefixes []string, suffixes []string, handle func(MinKlineRecord) error) error {
+	for _, suffix := range suffixes {
+		if _, err := minRecordDecoder(suffix); err != nil {
+			return err
+		}
 	}
 
-	files, err := collectFiles(filePath, validPrefixes, suffix)
+	files, err := collectFiles(filePath, validPrefixes, suffixes...)
 	if err != nil {
 		return err
 	}
@@ -181,8 +182,9 @@ func StreamMinFiles(filePath string, validPrefixes []string, suffix string, hand
 				producerWg.Done()
 			}()
 
-			if err := scanRecords(filename, suffix, func(recordBytes []byte, symbol string) {
-				record, err := processMinRecordValue(recordBytes, symbol)
+			decode, _ := minRecordDecoder(filepath.Ext(filename))
+			if err := scanRecords(filename, func(recordBytes []byte, symbol string) {
+				record, err := decode(recordBytes, symbol)
 				if err != nil {
 					rowChan <- minRowData{Err: fmt.Errorf("failed to process record in %s: %w", filename, err)}
 					return
@@ -204,25 +206,28 @@ func StreamMinFiles(filePath string, validPrefixes []string, suffix string, hand
 	return nil
 }
 
-// 将通达信的 .day, .01, 或 .5 文件转换为CSV文件。
-func ConvertFiles2Csv(filePath string, validPrefixes []string, outputCSV string, suffix string) (string, error) {
-	// 1. 根据文件后缀选择CSV头部和记录处理器
+// 将通达信的 .day, .01/.lc1, 或 .5/.lc5 文件转换为CSV文件，多个后缀须同为日线或同为分时。
+func ConvertFiles2Csv(filePath string, validPrefixes []string, outputCSV string, suffixes ...string) (string, error) {
+	// 1. 根据文件后缀选择CSV头部，记录处理器按每个文件的后缀选择
 	var csvHeader string
-	var recordProcessor func(recordBytes []byte, symbol string) (string, error)
-
-	switch suffix {
-	case ".day":
-		csvHeader = "symbol,open,high,low,close,amount,volume,date\n"
-		recordProcessor = processDayRecord
-	case ".01", ".5":
-		csvHeader = "symbol,open,high,low,close,amount,volume,datetime\n"
-		recordProcessor = processMinRecord
-	default:
-		return "", fmt.Errorf("unsupported file suffix: '%s'. Supported are .day, .01, .5", suffix)
+	for _, suffix := range suffixes {
+		var header string
+		switch suffix {
+		case ".day":
+			header = "symbol,open,high,low,close,amount,volume,date\n"
+		case ".01", ".5", ".lc1", ".lc5":
+			header = "symbol,open,high,low,close,amount,volume,datetime\n"
+		default:
+			return "", fmt.Errorf("unsupported file suffix: '%s'. Supported are .day, .01, .5, .lc1, .lc5", suffix)
+		}
+		if csvHeader != "" && csvHeader != header {
+			return "", fmt.Errorf("cannot mix day and minute suffixes: %v", suffixes)
+		}
+		csvHeader = header
 	}
 
 	// 2. 收集所有匹配的文件
-	files, err := collectFiles(filePath, validPrefixes, suffix)
+	files, err := collectFiles(filePath, validPrefixes, suffixes...)
 	if err != nil {
 		return "", err
 	}
@@ -290,7 +295,7 @@ func ConvertFiles2Csv(filePath string, validPrefixes []string, outputCSV string,
 				producerWg.Done()
 			}()
 			// 调用通用的文件处理函数，它会将结果发送到channel
-			processAndProduce(filename, suffix, rowChan, recordProcessor)
+			processAndProduce(filename, rowChan, recordProcessor(filepath.Ext(filename)))
 		}(file)
 	}
 
@@ -306,14 +311,20 @@ func ConvertFiles2Csv(filePath string, validPrefixes []string, outputCSV string,
 	return outputCSV, nil
 }
 
-// collectFiles 遍历目录并收集所有符合条件的文件路径。
-func collectFiles(filePath string, validPrefixes []string, suffix string) ([]string, error) {
+// collectFiles 遍历目录并收集所有符合条件（任一后缀）的文件路径。
+func collectFiles(filePath string, validPrefixes []string, suffixes ...string) ([]string, error) {
 	var files []string
 	err := filepath.WalkDir(filePath, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		if !d.IsDir() && strings.HasSuffix(path, suffix) {
+		if d.IsDir() {
+			return nil
+		}
+		for _, suffix := range suffixes {
+			if filepath.Ext(path) != suffix {
+				continue
+			}
 			symbol := strings.TrimSuffix(filepath.Base(path), suffix)
 			for _, prefix := range validPrefixes {
 				if strings.HasPrefix(symbol, prefix) {
@@ -328,12 +339,12 @@ func collectFiles(filePath string, validPrefixes []string, suffix string) ([]str
 		return nil, fmt.Errorf("failed to traverse directory %s: %w", filePath, err)
 	}
 	if len(files) == 0 {
-		return nil, fmt.Errorf("no valid '%s' files found with the given prefixes", suffix)
+		return nil, fmt.Errorf("no valid '%s' files found with the given prefixes", strings.Join(suffixes, "', '"))
 	}
 	return files, nil
 }
 
-func scanRecords(filename, suffix string, handle func(recordBytes []byte, symbol string)) error {
+func scanRecords(filename string, handle func(recordBytes []byte, symbol string)) error {
 	fileInfo, err := os.Stat(filename)
 	if err != nil {
 		return fmt.Errorf("could not stat file %s: %w", filename, err)
@@ -348,7 +359,7 @@ func scanRecords(filename, suffix string, handle func(recordBytes []byte, symbol
 	}
 	defer inFile.Close()
 
-	symbol := strings.TrimSuffix(filepath.Base(filename), suffix)
+	symbol := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
 	buffer := make([]byte, readBufferSize)
 	var carry [recordSize]byte
 	carryLen := 0
@@ -401,8 +412,8 @@ func scanRecords(filename, suffix string, handle func(recordBytes []byte, symbol
 }
 
 // processAndProduce 读取单个文件，使用指定的处理器函数解析记录，并将结果发送到channel。
-func processAndProduce(filename, suffix string, rowChan chan<- RowData, processor func([]byte, string) (string, error)) {
-	if err := scanRecords(filename, suffix, func(recordBytes []byte, symbol string) {
+func processAndProduce(filename string, rowChan chan<- RowData, processor func([]byte, string) (string, error)) {
+	if err := scanRecords(filename, func(recordBytes []byte, symbol string) {
 		csvLine, err := processor(recordBytes, symbol)
 		if err != nil {
 			rowChan <- RowData{Err: fmt.Errorf("failed to process record in %s: %w", filename, err)}
@@ -424,6 +435,48 @@ func writeBatchToFile(file *os.File, batch []string) error {
 
 // --- 特定记录处理函数 ---
 
+// recordProcessor 根据文件后缀返回转换 CSV 行的处理器
+func recordProcessor(suffix string) func([]byte, string) (string, error) {
+	if suffix == ".day" {
+		return processDayRecord
+	}
+	decode, err := minRecordDecoder(suffix)
+	if err != nil {
+		return func([]byte, string) (string, error) { return "", err }
+	}
+	return func(data []byte, symbol string) (string, error) {
+		record, err := decode(data, symbol)
+		if err != nil {
+			return "", err
+		}
+		return formatMinKline(record), nil
+	}
+}
+
+// minRecordDecoder 根据文件后缀选择分时记录解码器
+func minRecordDecoder(suffix string) (func([]byte, string) (MinKlineRecord, error), error) {
+	switch suffix {
+	case ".01", ".5":
+		return processMinRecordValue, nil
+	case ".lc1", ".lc5":
+		return processLcRecordValue, nil
+	default:
+		return nil, fmt.Errorf("unsupported file suffix: '%s'. Supported are .01, .5, .lc1, .lc5", suffix)
+	}
+}
+
+// isFloatDayRecord 判断 .day 记录的价格是否为 float32 存储（新版客户端）。
+// 整数价格以分为单位，0x30000000 分对应八百万元，不会出现在真实行情中；
+// 而常见价格的 float32 编码都落在该值之上。
+func isFloatDayRecord(data []byte) bool {
+	open := binary.LittleEndian.Uint32(data[4:8])
+	if open < 0x30000000 {
+		return false
+	}
+	v := math.Float32frombits(open)
+	return v > 0 && v < 1e7
+}
+
 func processDayRecordValue(data []byte, symbol string) (DayKlineRecord, error) {
 	var record model.DayfileRecord
 	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &record); err != nil {
@@ -434,12 +487,26 @@ func processDayRecordValue(data []byte, symbol string) (DayKlineRecord, error) {
 		return DayKlineRecord{}, err
 	}
 
+	if isFloatDayRecord(data) {
+		return DayKlineRecord{
+			Symbol: symbol,
+			Open:   roundPrice(float64(math.Float32frombits(record.Open))),
+			High:   roundPrice(float64(math.Float32frombits(record.High))),
+			Low:    roundPrice(float64(math.Float32frombits(record.Low))),
+			Close:  roundPrice(float64(math.Float32frombits(record.Close))),
+			Amount: float64(record.Amount),
+			Volume: int64(record.Volume),
+			Date:   date,
+		}, nil
+	}
+
+	scale := PriceScale(symbol)
 	return DayKlineRecord{
 		Symbol: symbol,
-		Open:   float64(record.Open) / 100,
-		High:   float64(record.High) / 100,
-		Low:    float64(record.Low) / 100,
-		Close:  float64(record.Close) / 100,
+		Open:   float64(record.Open) / scale,
+		High:   float64(record.High) / scale,
+		Low:    float64(record.Low) / scale,
+		Close:  float64(record.Close) / scale,
 		Amount: float64(record.Amount),
 		Volume: int64(record.Volume),
 		Date:   date,
@@ -447,23 +514,11 @@ func processDayRecordValue(data []byte, symbol string) (DayKlineRecord, error) {
 }
 
 func processDayRecord(data []byte, symbol string) (string, error) {
-	var record model.DayfileRecord
-	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &record); err != nil {
-		return "", fmt.Errorf("binary read failed: %w", err)
-	}
-	dateStr, err := formatDate(record.Date)
+	record, err := processDayRecordValue(data, symbol)
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("%s,%.2f,%.2f,%.2f,%.2f,%.2f,%d,%s\n",
-		symbol,
-		float64(record.Open)/100,
-		float64(record.High)/100,
-		float64(record.Low)/100,
-		float64(record.Close)/100,
-		record.Amount,
-		record.Volume,
-		dateStr), nil
+	return formatDayKline(record), nil
 }
 
 func processMinRecordValue(data []byte, symbol string) (MinKlineRecord, error) {
@@ -475,60 +530,66 @@ func processMinRecordValue(data []byte, symbol string) (MinKlineRecord, error) {
 	if err != nil {
 		return MinKlineRecord{}, err
 	}
+	scale := PriceScale(symbol)
 	return MinKlineRecord{
 		Symbol:   symbol,
-		Open:     float64(record.Open) / 100,
-		High:     float64(record.High) / 100,
-		Low:      float64(record.Low) / 100,
-		Close:    float64(record.Close) / 100,
+		Open:     float64(record.Open) / scale,
+		High:     float64(record.High) / scale,
+		Low:      float64(record.Low) / scale,
+		Close:    float64(record.Close) / scale,
 		Amount:   float64(record.Amount),
 		Volume:   int64(record.Volume),
 		Datetime: dateTime,
 	}, nil
 }
 
-func processMinRecord(data []byte, symbol string) (string, error) {
-	var record model.MinfileRecord
+// processLcRecordValue 解析 .lc1/.lc5 记录，价格为 float32。
+// 日期和时间字段与 .01/.5 的打包方式相同，见 parseDateTime
+func processLcRecordValue(data []byte, symbol string) (MinKlineRecord, error) {
+	var record model.LcfileRecord
 	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &record); err != nil {
-		return "", fmt.Errorf("binary read failed: %w", err)
+		return MinKlineRecord{}, fmt.Errorf("binary read failed: %w", err)
 	}
-	dateTimeStr, err := formatDateTime(record.DateRaw, record.TimeRaw)
+	dateTime, err := parseDateTime(record.DateRaw, record.TimeRaw)
 	if err != nil {
-		return "", err
+		return MinKlineRecord{}, err
 	}
-	return fmt.Sprintf("%s,%.2f,%.2f,%.2f,%.2f,%.2f,%d,%s\n",
-		symbol,
-		float64(record.Open)/100,
-		float64(record.High)/100,
-		float64(record.Low)/100,
-		float64(record.Close)/100,
-		record.Amount,
-		record.Volume,
-		dateTimeStr), nil
+	return MinKlineRecord{
+		Symbol:   symbol,
+		Open:     roundPrice(float64(record.Open)),
+		High:     roundPrice(float64(record.High)),
+		Low:      roundPrice(float64(record.Low)),
+		Close:    roundPrice(float64(record.Close)),
+		Amount:   float64(record.Amount),
+		Volume:   int64(record.Volume),
+		Datetime: dateTime,
+	}, nil
 }
 
-func formatDate(date uint32) (string, error) {
-	d := int(date)
-	year, month, day := d/10000, (d%10000)/100, d%100
-	if year < 1990 || year > 2100 || month < 1 || month > 12 || day < 1 || day > 31 {
-		return "", fmt.Errorf("invalid date value: %08d", date)
-	}
-	return fmt.Sprintf("%04d-%02d-%02d", year, month, day), nil
+func formatDayKline(r DayKlineRecord) string {
+	d := priceDecimals(r.Symbol)
+	return fmt.Sprintf("%s,%.*f,%.*f,%.*f,%.*f,%.2f,%d,%s\n",
+		r.Symbol,
+		d, r.Open,
+		d, r.High,
+		d, r.Low,
+		d, r.Close,
+		r.Amount,
+		r.Volume,
+		r.Date.Format("2006-01-02"))
 }
 
-func formatDateTime(dateRaw, timeRaw uint16) (string, error) {
-	year := int(dateRaw)/2048 + 2004
-	month := (int(dateRaw) % 2048) / 100
-	day := (int(dateRaw) % 2048) % 100
-	hour := int(timeRaw) / 60
-	minute := int(timeRaw) % 60
-	if year < 1990 || year > 2100 || month < 1 || month > 12 || day < 1 || day > 31 {
-		return "", fmt.Errorf("invalid date value from raw: %d", dateRaw)
-	}
-	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
-		return "", fmt.Errorf("invalid time value from raw: %d", timeRaw)
-	}
-	return fmt.Sprintf("%04d-%02d-%02d %02d:%02d", year, month, day, hour, minute), nil
+func formatMinKline(r MinKlineRecord) string {
+	d := priceDecimals(r.Symbol)
+	return fmt.Sprintf("%s,%.*f,%.*f,%.*f,%.*f,%.2f,%d,%s\n",
+		r.Symbol,
+		d, r.Open,
+		d, r.High,
+		d, r.Low,
+		d, r.Close,
+		r.Amount,
+		r.Volume,
+		r.Datetime.Format("2006-01-02 15:04"))
 }
 
 func parseDate(date uint32) (time.Time, error) {
@@ -540,6 +601,8 @@ func parseDate(date uint32) (time.Time, error) {
 	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC), nil
 }
 
+// parseDateTime 解析分时记录的日期和时间：
+// dateRaw = (year-2004)*2048 + month*100 + day，timeRaw 为当日分钟数 (hour*60 + minute)
 func parseDateTime(dateRaw, timeRaw uint16) (time.Time, error) {
 	year := int(dateRaw)/2048 + 2004
 	month := (int(dateRaw) % 2048) / 100
@@ -0,0 +1,148 @@
+package tdx
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// packDate 按 parseDateTime 注释中的方式打包分时记录日期
+func packDate(year, month, day int) uint16 {
+	return uint16((year-2004)*2048 + month*100 + day)
+}
+
+// minuteRecord 构造 .01/.5 或 .lc1/.lc5 记录，价格按 prices 原样写入 4 字节
+func minuteRecord(dateRaw, timeRaw uint16, prices [4]uint32, amount float32, volume uint32) []byte {
+	b := make([]byte, 32)
+	binary.LittleEndian.PutUint16(b[0:2], dateRaw)
+	binary.LittleEndian.PutUint16(b[2:4], timeRaw)
+	for i, p := range prices {
+		binary.LittleEndian.PutUint32(b[4+i*4:8+i*4], p)
+	}
+	putFloat32(b[20:24], amount)
+	binary.LittleEndian.PutUint32(b[24:28], volume)
+	return b
+}
+
+func dayRecord(date uint32, prices [4]uint32, amount float32, volume uint32) []byte {
+	b := make([]byte, 32)
+	binary.LittleEndian.PutUint32(b[0:4], date)
+	for i, p := range prices {
+		binary.LittleEndian.PutUint32(b[4+i*4:8+i*4], p)
+	}
+	putFloat32(b[20:24], amount)
+	binary.LittleEndian.PutUint32(b[24:28], volume)
+	return b
+}
+
+func floatBits(prices ...float32) [4]uint32 {
+	var res [4]uint32
+	for i, p := range prices {
+		res[i] = math.Float32bits(p)
+	}
+	return res
+}
+
+func TestParseDateTime(t *testing.T) {
+	// 2024-05-06 09:31: (2024-2004)*2048 + 5*100 + 6 = 41466，9*60+31 = 571
+	got, err := parseDateTime(41466, 571)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 5, 6, 9, 31, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("parseDateTime = %v, want %v", got, want)
+	}
+	if _, err := parseDateTime(packDate(2024, 13, 1), 571); err == nil {
+		t.Error("month 13: want error")
+	}
+	if _, err := parseDateTime(41466, 24*60); err == nil {
+		t.Error("minute 1440: want error")
+	}
+}
+
+func TestProcessMinRecordValue(t *testing.T) {
+	b := minuteRecord(packDate(2024, 5, 6), 9*60+31, [4]uint32{1023, 1052, 987, 1001}, 1234567.5, 98765)
+	got, err := processMinRecordValue(b, "sz000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MinKlineRecord{
+		Symbol: "sz000001", Open: 10.23, High: 10.52, Low: 9.87, Close: 10.01,
+		Amount: 1234567.5, Volume: 98765,
+		Datetime: time.Date(2024, 5, 6, 9, 31, 0, 0, time.UTC),
+	}
+	if got != want {
+		t.Fatalf("decode = %+v, want %+v", got, want)
+	}
+}
+
+func TestProcessLcRecordValue(t *testing.T) {
+	// 与 .01 记录相同的日期时间字段，价格改为 float32
+	b := minuteRecord(packDate(2024, 5, 6), 14*60+55, floatBits(10.25, 10.5, 9.75, 10.125), 1234567.5, 98765)
+	got, err := processLcRecordValue(b, "sz000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MinKlineRecord{
+		Symbol: "sz000001", Open: 10.25, High: 10.5, Low: 9.75, Close: 10.125,
+		Amount: 1234567.5, Volume: 98765,
+		Datetime: time.Date(2024, 5, 6, 14, 55, 0, 0, time.UTC),
+	}
+	if got != want {
+		t.Fatalf("decode = %+v, want %+v", got, want)
+	}
+}
+
+func TestProcessDayRecordValue(t *testing.T) {
+	want := DayKlineRecord{
+		Symbol: "sz000001", Open: 10.25, High: 10.5, Low: 9.75, Close: 10.13,
+		Amount: 1234567.5, Volume: 98765,
+		Date: time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC),
+	}
+	cases := []struct {
+		name   string
+		prices [4]uint32
+		float  bool
+	}{
+		{"integer", [4]uint32{1025, 1050, 975, 1013}, false},
+		{"float", floatBits(10.25, 10.5, 9.75, 10.13), true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := dayRecord(20240506, c.prices, 1234567.5, 98765)
+			if got := isFloatDayRecord(b); got != c.float {
+				t.Fatalf("isFloatDayRecord = %v, want %v", got, c.float)
+			}
+			got, err := processDayRecordValue(b, "sz000001")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Fatalf("decode = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestIsFloatDayRecord(t *testing.T) {
+	cases := []struct {
+		name string
+		open uint32
+		want bool
+	}{
+		{"integer cents", 123456, false},
+		{"largest integer", 0x2FFFFFFF, false},
+		{"float price", math.Float32bits(10.25), true},
+		{"float penny", math.Float32bits(0.01), true},
+		{"float negative", math.Float32bits(-10.25), false},
+		{"float too large", math.Float32bits(2e7), false},
+		{"float NaN", 0x7FC00000, false},
+	}
+	for _, c := range cases {
+		b := dayRecord(20240506, [4]uint32{c.open}, 0, 0)
+		if got := isFloatDayRecord(b); got != c.want {
+			t.Errorf("%s (%#08x): got %v, want %v", c.name, c.open, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package tdx
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 四代行情 (g4day) 压缩包解码，替代 datatool day create。
+//
+// 压缩包内每个市场一个 refmhq 文件，文件名以市场开头 (sh/sz/bj)，
+// 记录为定长 64 字节，小端：
+//
+//	0   [6]byte  证券代码 (ASCII)
+//	6   [2]byte  保留
+//	8   uint32   日期 yyyymmdd
+//	12  float32  开盘价
+//	16  float32  最高价
+//	20  float32  最低价
+//	24  float32  收盘价
+//	28  float32  昨收价
+//	32  float32  成交额(元)
+//	36  uint32   成交量
+//	40  [24]byte 保留
+const refmhqRecordSize = 64
+
+// StreamDayZip 直接解码四代行情压缩包，通过回调逐条输出日线记录。
+// 停牌（无成交）的记录会被跳过，与 datatool 生成的 .day 文件保持一致。
+// 注意：回调在调用方 goroutine 内串行执行。
+func StreamDayZip(zipPath string, validPrefixes []string, handle func(DayKlineRecord) error) error {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("failed to open zip %s: %w", zipPath, err)
+	}
+	defer r.Close()
+
+	found := 0
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		mkt := refmhqMarket(f.Name)
+		if mkt == "" {
+			continue
+		}
+
+		data, err := readZipEntry(f)
+		if err != nil {
+			return fmt.Errorf("failed to read %s in %s: %w", f.Name, zipPath, err)
+		}
+		if len(data)%refmhqRecordSize != 0 {
+			return fmt.Errorf("invalid refmhq file %s: size %d is not a multiple of %d", f.Name, len(data), refmhqRecordSize)
+		}
+		found++
+
+		for off := 0; off < len(data); off += refmhqRecordSize {
+			record, ok, err := decodeRefmhqRecord(data[off:off+refmhqRecordSize], mkt)
+			if err != nil {
+				return fmt.Errorf("failed to decode record %d in %s: %w", off/refmhqRecordSize, f.Name, err)
+			}
+			if !ok || !hasValidPrefix(record.Symbol, validPrefixes) {
+				continue
+			}
+			if err := handle(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	if found == 0 {
+		return fmt.Errorf("no refmhq files found in %s", zipPath)
+	}
+	return nil
+}
+
+// ConvertDayZip2Csv 将四代行情压缩包转换为与 .day 相同格式的 CSV 文件。
+func ConvertDayZip2Csv(zipPath string, validPrefixes []string, outputCSV string) (string, error) {
+	outFile, err := os.Create(outputCSV)
+	if err != nil {
+		return "", fmt.Errorf("failed to create CSV file %s: %w", outputCSV, err)
+	}
+	defer outFile.Close()
+
+	if _, err := outFile.WriteString("symbol,open,high,low,close,amount,volume,date\n"); err != nil {
+		return "", fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	batch := make([]string, 0, writeBatchSize)
+	err = StreamDayZip(zipPath, validPrefixes, func(record DayKlineRecord) error {
+		batch = append(batch, formatDayKline(record))
+		if len(batch) >= writeBatchSize {
+			if err := writeBatchToFile(outFile, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(batch) > 0 {
+		if err := writeBatchToFile(outFile, batch); err != nil {
+			return "", err
+		}
+	}
+	return outputCSV, nil
+}
+
+func decodeRefmhqRecord(b []byte, mkt string) (DayKlineRecord, bool, error) {
+	code := strings.TrimSpace(string(trimZero(b[0:6])))
+	if code == "" {
+		return DayKlineRecord{}, false, nil
+	}
+
+	date, err := parseDate(binary.LittleEndian.Uint32(b[8:12]))
+	if err != nil {
+		return DayKlineRecord{}, false, err
+	}
+
+	record := DayKlineRecord{
+		Symbol: mkt + code,
+		Open:   roundPrice(readFloat32(b[12:16])),
+		High:   roundPrice(readFloat32(b[16:20])),
+		Low:    roundPrice(readFloat32(b[20:24])),
+		Close:  roundPrice(readFloat32(b[24:28])),
+		Amount: readFloat32(b[32:36]),
+		Volume: int64(binary.LittleEndian.Uint32(b[36:40])),
+		Date:   date,
+	}
+
+	// 停牌或无成交
+	if record.Close <= 0 || record.Volume == 0 {
+		return DayKlineRecord{}, false, nil
+	}
+	return record, true, nil
+}
+
+// refmhqMarket 从压缩包内文件名解析市场，未知市场返回空串。
+func refmhqMarket(name string) string {
+	base := strings.ToLower(filepath.Base(name))
+	for _, mkt := range []string{"sh", "sz", "bj"} {
+		if strings.HasPrefix(base, mkt) {
+			return mkt
+		}
+	}
+	return ""
+}
+
+func readZipEntry(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func readFloat32(b []byte) float64 {
+	return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+}
+
+// roundPrice 消除 float32 转换带来的尾差，保留 3 位小数。
+func roundPrice(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+func hasValidPrefix(symbol string, validPrefixes []string) bool {
+	for _, prefix := range validPrefixes {
+		if strings.HasPrefix(symbol, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package tdx
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var goldenPrefixes = []string{"sh", "sz", "bj"}
+
+// goldenZip 返回 dir 下唯一的 yyyymmdd.zip，没有样本时跳过测试
+func goldenZip(t *testing.T, dir string) string {
+	t.Helper()
+	zips, err := filepath.Glob(filepath.Join(dir, "*.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zips) == 0 {
+		t.Skipf("no fixture in %s, see the comment on the test for how to create one", dir)
+	}
+	if len(zips) > 1 {
+		t.Fatalf("expected one zip in %s, got %v", dir, zips)
+	}
+	return zips[0]
+}
+
+func closeTo(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+// writeZip 生成压缩包，entries 按顺序写入
+func writeZip(t *testing.T, path string, entries ...zipEntry) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for _, e := range entries {
+		fw, err := w.Create(e.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write(e.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type zipEntry struct {
+	name string
+	data []byte
+}
+
+func putFloat32(b []byte, v float32) {
+	binary.LittleEndian.PutUint32(b, math.Float32bits(v))
+}
+
+// refmhqRecord 按文件头注释中的偏移构造一条记录，保留字节填充 0xEE，确认解码不读取它们
+func refmhqRecord(code string, date uint32, open, high, low, close, preClose, amount float32, volume uint32) []byte {
+	b := make([]byte, refmhqRecordSize)
+	for i := range b {
+		b[i] = 0xEE
+	}
+	copy(b[0:6], append([]byte(code), make([]byte, 6)...)[:6])
+	binary.LittleEndian.PutUint32(b[8:12], date)
+	putFloat32(b[12:16], open)
+	putFloat32(b[16:20], high)
+	putFloat32(b[20:24], low)
+	putFloat32(b[24:28], close)
+	putFloat32(b[28:32], preClose)
+	putFloat32(b[32:36], amount)
+	binary.LittleEndian.PutUint32(b[36:40], volume)
+	return b
+}
+
+func TestDecodeRefmhqRecord(t *testing.T) {
+	// 每个价格字段取不同的值，偏移错位时会被发现
+	b := refmhqRecord("600000", 20251201, 10.01, 10.52, 9.87, 10.23, 9.99, 1234567.5, 98765)
+	got, ok, err := decodeRefmhqRecord(b, "sh")
+	if err != nil || !ok {
+		t.Fatalf("decode = %v, %v", ok, err)
+	}
+	want := DayKlineRecord{
+		Symbol: "sh600000",
+		Open:   10.01,
+		High:   10.52,
+		Low:    9.87,
+		Close:  10.23,
+		Amount: 1234567.5,
+		Volume: 98765,
+		Date:   time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if got != want {
+		t.Errorf("decoded %+v, want %+v", got, want)
+	}
+
+	cases := []struct {
+		name string
+		b    []byte
+	}{
+		{"suspended", refmhqRecord("600001", 20251201, 0, 0, 0, 10.23, 10.23, 0, 0)},
+		{"no trade", refmhqRecord("600002", 20251201, 10, 10, 10, 10, 10, 0, 0)},
+		{"empty code", refmhqRecord("", 20251201, 10, 10, 10, 10, 10, 1000, 100)},
+	}
+	for _, c := range cases {
+		if _, ok, err := decodeRefmhqRecord(c.b, "sh"); ok || err != nil {
+			t.Errorf("%s: ok = %v, err = %v, want skipped", c.name, ok, err)
+		}
+	}
+
+	if _, _, err := decodeRefmhqRecord(refmhqRecord("600000", 20251301, 10, 10, 10, 10, 10, 1000, 100), "sh"); err == nil {
+		t.Error("invalid date: want error")
+	}
+}
+
+func TestStreamDayZip(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "20251201.zip")
+	sh := append(refmhqRecord("600000", 20251201, 10.01, 10.52, 9.87, 10.23, 9.99, 1234567.5, 98765),
+		refmhqRecord("600001", 20251201, 0, 0, 0, 5, 5, 0, 0)...)
+	sz := refmhqRecord("159915", 20251201, 2.345, 2.361, 2.331, 2.352, 2.34, 5000, 2000)
+	bj := refmhqRecord("830799", 20251201, 20, 21, 19, 20.5, 20, 10000, 500)
+	writeZip(t, zipPath,
+		zipEntry{"readme.txt", []byte("not a refmhq file")},
+		zipEntry{"sh.dat", sh},
+		zipEntry{"SZ.dat", sz},
+		zipEntry{"bj.dat", bj},
+	)
+
+	var got []DayKlineRecord
+	if err := StreamDayZip(zipPath, []string{"sh", "sz"}, func(r DayKlineRecord) error {
+		got = append(got, r)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	want := []DayKlineRecord{
+		{Symbol: "sh600000", Open: 10.01, High: 10.52, Low: 9.87, Close: 10.23, Amount: 1234567.5, Volume: 98765, Date: day},
+		{Symbol: "sz159915", Open: 2.345, High: 2.361, Low: 2.331, Close: 2.352, Amount: 5000, Volume: 2000, Date: day},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d records %+v, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("record %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	bad := filepath.Join(dir, "20251202.zip")
+	writeZip(t, bad, zipEntry{"sh.dat", sh[:refmhqRecordSize+10]})
+	if err := StreamDayZip(bad, goldenPrefixes, func(DayKlineRecord) error { return nil }); err == nil {
+		t.Error("truncated refmhq file: want error")
+	}
+}
+
+// TestDayZipMatchesDatatool 用真实样本校验 refmhq 记录布局。样本放在 testdata/g4day：
+//
+//	testdata/g4day/20251201.zip     从 g4day 下载的四代行情包，可只保留各市场 refmhq 的前几条记录
+//	testdata/g4day/sh600000.day ... datatool day create 20251201 对同一天生成的日线文件
+//
+// 压缩包解码出的每条记录须与 .day 文件中同一证券同一天的记录一致
+func TestDayZipMatchesDatatool(t *testing.T) {
+	dir := filepath.Join("testdata", "g4day")
+	zipPath := goldenZip(t, dir)
+	date, err := TickZipDate(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := make(map[string]DayKlineRecord)
+	if err := StreamDayFiles(dir, goldenPrefixes, func(r DayKlineRecord) error {
+		if r.Date.Equal(date) {
+			want[r.Symbol] = r
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(want) == 0 {
+		t.Fatalf("no .day records dated %s in %s", date.Format("2006-01-02"), dir)
+	}
+
+	compared := 0
+	if err := StreamDayZip(zipPath, goldenPrefixes, func(got DayKlineRecord) error {
+		w, ok := want[got.Symbol]
+		if !ok {
+			return nil
+		}
+		compared++
+		if !got.Date.Equal(w.Date) ||
+			!closeTo(got.Open, w.Open, 0.0005) || !closeTo(got.High, w.High, 0.0005) ||
+			!closeTo(got.Low, w.Low, 0.0005) || !closeTo(got.Close, w.Close, 0.0005) ||
+			got.Volume != w.Volume || !closeTo(got.Amount, w.Amount, math.Max(1, w.Amount*1e-6)) {
+			t.Errorf("%s: decoded %+v, datatool %+v", got.Symbol, got, w)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if compared == 0 {
+		t.Fatalf("no symbol in %s has a matching .day file", zipPath)
+	}
+	t.Logf("compared %d records", compared)
+}
@@ -0,0 +1,279 @@
+package tdx
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 四代分笔 (g4tic) 压缩包解码，替代 datatool tick create / min create。
+//
+// 压缩包文件名为交易日 yyyymmdd.zip，包内每个证券一个文件，文件名为
+// {mkt}{code}，扩展名忽略。记录为定长 16 字节，小端：
+//
+//	0   uint16  分钟数 (hour*60 + minute)
+//	2   uint16  秒，未知时为 0xFFFF
+//	4   uint32  成交价 (厘，即 1/1000 元)
+//	8   uint32  成交量 (股)
+//	12  uint8   买卖方向 0 买 / 1 卖 / 2 中性
+//	13  [3]byte 保留
+const tickRecordSize = 16
+
+type TickRecord struct {
+	Symbol    string
+	Datetime  time.Time
+	Price     float64
+	Volume    int64
+	Amount    float64
+	Direction int8
+}
+
+// StreamTickZip 解码四代分笔压缩包，按证券逐个回调该证券当日全部分笔（按时间排序）。
+// 注意：回调在调用方 goroutine 内串行执行。
+func StreamTickZip(zipPath string, validPrefixes []string, handle func(symbol string, ticks []TickRecord) error) error {
+	tradeDate, err := TickZipDate(zipPath)
+	if err != nil {
+		return err
+	}
+
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("failed to open zip %s: %w", zipPath, err)
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		base := filepath.Base(f.Name)
+		symbol := strings.ToLower(strings.TrimSuffix(base, filepath.Ext(base)))
+		if refmhqMarket(symbol) == "" || !hasValidPrefix(symbol, validPrefixes) {
+			continue
+		}
+
+		data, err := readZipEntry(f)
+		if err != nil {
+			return fmt.Errorf("failed to read %s in %s: %w", f.Name, zipPath, err)
+		}
+		if len(data)%tickRecordSize != 0 {
+			return fmt.Errorf("invalid tick file %s: size %d is not a multiple of %d", f.Name, len(data), tickRecordSize)
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		ticks := make([]TickRecord, 0, len(data)/tickRecordSize)
+		for off := 0; off < len(data); off += tickRecordSize {
+			tick, err := decodeTickRecord(data[off:off+tickRecordSize], symbol, tradeDate)
+			if err != nil {
+				return fmt.Errorf("failed to decode record %d in %s: %w", off/tickRecordSize, f.Name, err)
+			}
+			if tick.Volume == 0 || tick.Price <= 0 {
+				continue
+			}
+			ticks = append(ticks, tick)
+		}
+		if len(ticks) == 0 {
+			continue
+		}
+
+		sort.SliceStable(ticks, func(i, j int) bool {
+			return ticks[i].Datetime.Before(ticks[j].Datetime)
+		})
+		if err := handle(symbol, ticks); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertTickZip2Csv 将四代分笔压缩包聚合为 1 分钟和 5 分钟 CSV，格式与 .01/.5 转换结果一致。
+func ConvertTickZip2Csv(zipPath string, validPrefixes []string, min1CSV, min5CSV string) error {
+	min1File, err := os.Create(min1CSV)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file %s: %w", min1CSV, err)
+	}
+	defer min1File.Close()
+
+	min5File, err := os.Create(min5CSV)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file %s: %w", min5CSV, err)
+	}
+	defer min5File.Close()
+
+	header := "symbol,open,high,low,close,amount,volume,datetime\n"
+	for _, f := range []*os.File{min1File, min5File} {
+		if _, err := f.WriteString(header); err != nil {
+			return fmt.Errorf("failed to write CSV header: %w", err)
+		}
+	}
+
+	return StreamTickZip(zipPath, validPrefixes, func(symbol string, ticks []TickRecord) error {
+		min1, err := AggregateTicks(ticks, 1)
+		if err != nil {
+			return err
+		}
+		if err := writeBatchToFile(min1File, formatMinKlines(min1)); err != nil {
+			return err
+		}
+		min5, err := AggregateTicks(ticks, 5)
+		if err != nil {
+			return err
+		}
+		return writeBatchToFile(min5File, formatMinKlines(min5))
+	})
+}
+
+// 交易时段（距零点的分钟数）
+const (
+	morningOpen    = 9*60 + 30
+	morningClose   = 11*60 + 30
+	afternoonOpen  = 13 * 60
+	afternoonClose = 15 * 60
+)
+
+// AggregateTicks 将单个证券一个交易日的分笔聚合为 period (1 或 5) 分钟 K 线，规则与 datatool min create 相同：
+//   - K 线以结束时间标记，9:30:xx 的成交属于 9:31
+//   - 集合竞价 (9:30 之前) 计入第一根 K 线
+//   - 11:30 的成交计入 11:30，15:00 及盘后成交计入 15:00
+//   - 无成交的分钟沿用上一根收盘价，成交量为 0，保证每日 240/48 根
+//
+// period 须能整除 30，保证 9:30 和 13:00 都落在 K 线边界上，否则返回错误
+func AggregateTicks(ticks []TickRecord, period int) ([]MinKlineRecord, error) {
+	if period <= 0 || 30%period != 0 {
+		return nil, fmt.Errorf("unsupported period %d, must divide 30", period)
+	}
+	if len(ticks) == 0 {
+		return nil, nil
+	}
+
+	symbol := ticks[0].Symbol
+	y, m, d := ticks[0].Datetime.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	labels := sessionLabels(period)
+	index := make(map[int]int, len(labels))
+	bars := make([]MinKlineRecord, len(labels))
+	filled := make([]bool, len(labels))
+	for i, label := range labels {
+		index[label] = i
+		bars[i] = MinKlineRecord{
+			Symbol:   symbol,
+			Datetime: day.Add(time.Duration(label) * time.Minute),
+		}
+	}
+
+	for _, tick := range ticks {
+		label := barLabel(tick.Datetime.Hour()*60+tick.Datetime.Minute(), period)
+		i, ok := index[label]
+		if !ok {
+			return nil, fmt.Errorf("tick of %s at %s has no %d-minute bar", symbol, tick.Datetime.Format("15:04:05"), period)
+		}
+		bar := &bars[i]
+		if !filled[i] {
+			bar.Open, bar.High, bar.Low = tick.Price, tick.Price, tick.Price
+			filled[i] = true
+		}
+		if tick.Price > bar.High {
+			bar.High = tick.Price
+		}
+		if tick.Price < bar.Low {
+			bar.Low = tick.Price
+		}
+		bar.Close = tick.Price
+		bar.Volume += tick.Volume
+		bar.Amount += tick.Amount
+	}
+
+	// 无成交的分钟沿用前收，开盘前无成交时使用首笔成交价
+	last := ticks[0].Price
+	for i := range bars {
+		if !filled[i] {
+			bars[i].Open, bars[i].High, bars[i].Low, bars[i].Close = last, last, last, last
+			continue
+		}
+		last = bars[i].Close
+	}
+	return bars, nil
+}
+
+// barLabel 返回某一分钟的成交所属 K 线的结束时间（距零点的分钟数）
+func barLabel(minute, period int) int {
+	var label int
+	switch {
+	case minute < morningOpen:
+		label = morningOpen + 1
+	case minute >= morningClose && minute < afternoonOpen:
+		label = morningClose
+	case minute >= afternoonClose:
+		label = afternoonClose
+	default:
+		label = minute + 1
+	}
+	if period > 1 {
+		label = (label + period - 1) / period * period
+	}
+	return label
+}
+
+func sessionLabels(period int) []int {
+	labels := make([]int, 0, 240/period)
+	for _, session := range [][2]int{{morningOpen, morningClose}, {afternoonOpen, afternoonClose}} {
+		for t := session[0] + period; t <= session[1]; t += period {
+			labels = append(labels, t)
+		}
+	}
+	return labels
+}
+
+func decodeTickRecord(b []byte, symbol string, tradeDate time.Time) (TickRecord, error) {
+	minute := int(binary.LittleEndian.Uint16(b[0:2]))
+	second := int(binary.LittleEndian.Uint16(b[2:4]))
+	if minute >= 24*60 {
+		return TickRecord{}, fmt.Errorf("invalid tick time value: %d", minute)
+	}
+	if second > 59 {
+		second = 0
+	}
+
+	price := float64(binary.LittleEndian.Uint32(b[4:8])) / 1000
+	volume := int64(binary.LittleEndian.Uint32(b[8:12]))
+
+	return TickRecord{
+		Symbol:    symbol,
+		Datetime:  tradeDate.Add(time.Duration(minute)*time.Minute + time.Duration(second)*time.Second),
+		Price:     price,
+		Volume:    volume,
+		Amount:    price * float64(volume),
+		Direction: int8(b[12]),
+	}, nil
+}
+
+// TickZipDate 从压缩包文件名 (yyyymmdd.zip) 解析交易日
+func TickZipDate(zipPath string) (time.Time, error) {
+	name := filepath.Base(zipPath)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if len(name) != 8 {
+		return time.Time{}, fmt.Errorf("cannot parse trade date from zip name %s, expected yyyymmdd.zip", zipPath)
+	}
+	date, err := strconv.ParseUint(name, 10, 32)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse trade date from zip name %s: %w", zipPath, err)
+	}
+	return parseDate(uint32(date))
+}
+
+func formatMinKlines(bars []MinKlineRecord) []string {
+	lines := make([]string, 0, len(bars))
+	for _, r := range bars {
+		lines = append(lines, formatMinKline(r))
+	}
+	return lines
+}
@@ -0,0 +1,289 @@
+package tdx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var tickDay = time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+func tickAt(clock string, price float64, volume int64) TickRecord {
+	t, err := time.Parse("15:04:05", clock)
+	if err != nil {
+		panic(err)
+	}
+	return TickRecord{
+		Symbol:   "sz000001",
+		Datetime: tickDay.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second),
+		Price:    price,
+		Volume:   volume,
+		Amount:   price * float64(volume),
+	}
+}
+
+func barsByLabel(bars []MinKlineRecord) map[string]MinKlineRecord {
+	res := make(map[string]MinKlineRecord, len(bars))
+	for _, b := range bars {
+		res[b.Datetime.Format("15:04")] = b
+	}
+	return res
+}
+
+func TestAggregateTicksSessions(t *testing.T) {
+	ticks := []TickRecord{
+		tickAt("09:25:00", 10.00, 100), // 集合竞价
+		tickAt("09:30:10", 10.10, 200),
+		tickAt("11:29:59", 10.20, 300),
+		tickAt("11:30:05", 10.30, 400),
+		tickAt("13:00:01", 10.40, 500),
+		tickAt("14:59:30", 10.50, 600),
+		tickAt("15:00:00", 10.60, 700),
+		tickAt("15:05:00", 10.70, 800), // 盘后
+	}
+
+	type bar struct {
+		open, high, low, close float64
+		volume                 int64
+	}
+	cases := []struct {
+		period int
+		count  int
+		want   map[string]bar
+	}{
+		{
+			period: 1,
+			count:  240,
+			want: map[string]bar{
+				"09:31": {10.00, 10.10, 10.00, 10.10, 300},
+				"09:32": {10.10, 10.10, 10.10, 10.10, 0},
+				"11:30": {10.20, 10.30, 10.20, 10.30, 700},
+				"13:01": {10.40, 10.40, 10.40, 10.40, 500},
+				"14:59": {10.40, 10.40, 10.40, 10.40, 0},
+				"15:00": {10.50, 10.70, 10.50, 10.70, 2100},
+			},
+		},
+		{
+			period: 5,
+			count:  48,
+			want: map[string]bar{
+				"09:35": {10.00, 10.10, 10.00, 10.10, 300},
+				"11:30": {10.20, 10.30, 10.20, 10.30, 700},
+				"13:05": {10.40, 10.40, 10.40, 10.40, 500},
+				"15:00": {10.50, 10.70, 10.50, 10.70, 2100},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%dmin", c.period), func(t *testing.T) {
+			bars, err := AggregateTicks(ticks, c.period)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(bars) != c.count {
+				t.Fatalf("got %d bars, want %d", len(bars), c.count)
+			}
+			first, last := bars[0].Datetime.Format("15:04"), bars[len(bars)-1].Datetime.Format("15:04")
+			if first != fmt.Sprintf("09:%02d", 30+c.period) || last != "15:00" {
+				t.Errorf("bars span %s-%s", first, last)
+			}
+
+			var total int64
+			for _, b := range bars {
+				total += b.Volume
+				if h := b.Datetime.Hour()*60 + b.Datetime.Minute(); h > morningClose && h <= afternoonOpen {
+					t.Errorf("bar labelled %s falls in the lunch break", b.Datetime.Format("15:04"))
+				}
+			}
+			if total != 3600 {
+				t.Errorf("total volume %d, want 3600", total)
+			}
+
+			got := barsByLabel(bars)
+			for label, w := range c.want {
+				g, ok := got[label]
+				if !ok {
+					t.Errorf("missing bar %s", label)
+					continue
+				}
+				if g.Open != w.open || g.High != w.high || g.Low != w.low || g.Close != w.close || g.Volume != w.volume {
+					t.Errorf("bar %s = %.2f/%.2f/%.2f/%.2f %d, want %+v", label, g.Open, g.High, g.Low, g.Close, g.Volume, w)
+				}
+			}
+		})
+	}
+}
+
+func TestAggregateTicksRejectsUnalignedPeriod(t *testing.T) {
+	for _, period := range []int{0, 7, 60} {
+		if bars, err := AggregateTicks([]TickRecord{tickAt("10:00:00", 10, 100)}, period); err == nil {
+			t.Errorf("period %d: got %d bars, want error", period, len(bars))
+		}
+	}
+}
+
+// TestTickZipMatchesDatatool 用真实样本校验分笔记录布局和聚合规则。样本放在 testdata/g4tic：
+//
+//	testdata/g4tic/20251201.zip       从 g4tic 下载的四代分笔包，可只保留几个证券
+//	testdata/g4tic/sz000001.lc1 ...   datatool min create 20251201 对同一天生成的 1/5 分钟文件
+//	                                  (.lc1/.lc5 或 .01/.5)
+//
+// 聚合出的每根 K 线须与 datatool 文件中同一证券同一时刻的 K 线一致
+// tickRecord 按文件头注释中的偏移构造一条记录，保留字节填充 0xEE，确认解码不读取它们
+func tickRecord(minute, second uint16, price, volume uint32, direction uint8) []byte {
+	b := make([]byte, tickRecordSize)
+	for i := range b {
+		b[i] = 0xEE
+	}
+	binary.LittleEndian.PutUint16(b[0:2], minute)
+	binary.LittleEndian.PutUint16(b[2:4], second)
+	binary.LittleEndian.PutUint32(b[4:8], price)
+	binary.LittleEndian.PutUint32(b[8:12], volume)
+	b[12] = direction
+	return b
+}
+
+func TestDecodeTickRecord(t *testing.T) {
+	got, err := decodeTickRecord(tickRecord(9*60+31, 7, 10235, 4200, 1), "sz000001", tickDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := TickRecord{
+		Symbol:    "sz000001",
+		Datetime:  time.Date(2025, 12, 1, 9, 31, 7, 0, time.UTC),
+		Price:     10.235,
+		Volume:    4200,
+		Amount:    10.235 * 4200,
+		Direction: 1,
+	}
+	if got != want {
+		t.Fatalf("decode = %+v, want %+v", got, want)
+	}
+
+	// 秒未知时记在整分钟
+	got, err = decodeTickRecord(tickRecord(14*60+57, 0xFFFF, 9870, 100, 2), "sz000001", tickDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Datetime.Equal(time.Date(2025, 12, 1, 14, 57, 0, 0, time.UTC)) || got.Direction != 2 {
+		t.Fatalf("unknown second: got %+v", got)
+	}
+
+	if _, err := decodeTickRecord(tickRecord(24*60, 0, 10000, 100, 0), "sz000001", tickDay); err == nil {
+		t.Fatal("minute 1440: want error")
+	}
+}
+
+func TestStreamTickZip(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "20251201.zip")
+	concat := func(records ...[]byte) []byte {
+		var b []byte
+		for _, r := range records {
+			b = append(b, r...)
+		}
+		return b
+	}
+	writeZip(t, zipPath,
+		zipEntry{"sz000001", concat(
+			tickRecord(9*60+32, 0, 10100, 200, 0),
+			tickRecord(9*60+30, 5, 10000, 0, 2), // 无成交量，跳过
+			tickRecord(9*60+31, 30, 10050, 100, 1),
+		)},
+		zipEntry{"sh600000.tic", concat(tickRecord(9*60+31, 0, 8000, 300, 0))},
+		zipEntry{"bj920000", concat(tickRecord(9*60+31, 0, 8000, 300, 0))},
+		zipEntry{"readme.txt", []byte("not a tick file")},
+	)
+
+	got := map[string][]string{}
+	err := StreamTickZip(zipPath, []string{"sh", "sz"}, func(symbol string, ticks []TickRecord) error {
+		for _, tick := range ticks {
+			got[symbol] = append(got[symbol], fmt.Sprintf("%s %.3f %d", tick.Datetime.Format("15:04:05"), tick.Price, tick.Volume))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"sz000001": {"09:31:30 10.050 100", "09:32:00 10.100 200"},
+		"sh600000": {"09:31:00 8.000 300"},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("ticks = %v, want %v", got, want)
+	}
+
+	// 文件长度不是记录长度的整数倍
+	writeZip(t, zipPath, zipEntry{"sz000001", make([]byte, tickRecordSize+3)})
+	if err := StreamTickZip(zipPath, []string{"sz"}, func(string, []TickRecord) error { return nil }); err == nil {
+		t.Fatal("truncated file: want error")
+	}
+}
+
+func TestTickZipMatchesDatatool(t *testing.T) {
+	dir := filepath.Join("testdata", "g4tic")
+	zipPath := goldenZip(t, dir)
+	date, err := TickZipDate(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, period := range []struct {
+		minutes  int
+		suffixes []string
+	}{
+		{1, []string{".lc1", ".01"}},
+		{5, []string{".lc5", ".5"}},
+	} {
+		want := make(map[string]MinKlineRecord)
+		for _, suffix := range period.suffixes {
+			files, _ := filepath.Glob(filepath.Join(dir, "*"+suffix))
+			if len(files) == 0 {
+				continue
+			}
+			if err := StreamMinFiles(dir, goldenPrefixes, []string{suffix}, func(r MinKlineRecord) error {
+				if r.Datetime.Truncate(24 * time.Hour).Equal(date) {
+					want[r.Symbol+r.Datetime.Format(" 15:04")] = r
+				}
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(want) == 0 {
+			t.Logf("no %d-minute datatool files in %s", period.minutes, dir)
+			continue
+		}
+
+		compared := 0
+		if err := StreamTickZip(zipPath, goldenPrefixes, func(symbol string, ticks []TickRecord) error {
+			bars, err := AggregateTicks(ticks, period.minutes)
+			if err != nil {
+				return err
+			}
+			for _, got := range bars {
+				w, ok := want[got.Symbol+got.Datetime.Format(" 15:04")]
+				if !ok {
+					continue
+				}
+				compared++
+				if !closeTo(got.Open, w.Open, 0.0005) || !closeTo(got.High, w.High, 0.0005) ||
+					!closeTo(got.Low, w.Low, 0.0005) || !closeTo(got.Close, w.Close, 0.0005) ||
+					got.Volume != w.Volume || !closeTo(got.Amount, w.Amount, math.Max(1, w.Amount*1e-6)) {
+					t.Errorf("%d min %s %s: aggregated %+v, datatool %+v",
+						period.minutes, symbol, got.Datetime.Format("15:04"), got, w)
+				}
+			}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if compared == 0 {
+			t.Errorf("%d min: no aggregated bar matches a datatool bar", period.minutes)
+		}
+		t.Logf("%d min: compared %d bars", period.minutes, compared)
+	}
+}
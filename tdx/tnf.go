@@ -6,6 +6,7 @@ import (
 	"io"
 	"math"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 )
@@ -30,6 +31,7 @@ type StockRecord struct {
 	NamePinyin string
 	Mkt        string
 	Scaling    float64
+	Date       time.Time // 文件头中的行情日期
 }
 
 // ---------- 基础工具 ----------
@@ -110,45 +112,45 @@ func readAllRecords(r io.Reader) ([]*StockRecord, error) {
 	return records, nil
 }
 
-func ReadTnfRecords(base string, cb func(*StockRecord, string)) {
-	sh := base + "/shs.tnf"
-	readTnf("sh", sh, cb)
-	sz := base + "/szs.tnf"
-	readTnf("sz", sz, cb)
-	bj := base + "/bjs.tnf"
-	readTnf("bj", bj, cb)
+// ReadTnfRecords 依次读取 base 目录下的 shs.tnf、szs.tnf、bjs.tnf，
+// 对每条记录回调证券信息及 parseCode 得到的细分类型。
+func ReadTnfRecords(base string, cb func(*StockRecord, string)) error {
+	for _, mkt := range []string{"sh", "sz", "bj"} {
+		path := filepath.Join(base, mkt+"s.tnf")
+		if err := readTnf(mkt, path, cb); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func readTnf(mkt, path string, cb func(*StockRecord, string)) {
+func readTnf(mkt, path string, cb func(*StockRecord, string)) error {
 	f, err := os.Open(path)
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to open tnf file %s: %w", path, err)
 	}
 	defer f.Close()
 
 	header, err := readTnfHeader(f)
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to read tnf header %s: %w", path, err)
 	}
-	fmt.Printf("Path: %s IP: %s Port: %d Date: %d Time: %d Parsed: %s\n",
-		path, header.IPRaw, header.Port, header.RawDate, header.RawTime, header.Timestamp.Format("2006-01-02 15:04:05"))
 
 	records, err := readAllRecords(f)
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to read tnf records %s: %w", path, err)
 	}
 
-	for i, r := range records {
+	for _, r := range records {
 		r.Mkt = mkt
-		if r.Typ == 3 { //2 指数 4 债券（国债/可转债/）3 ETF/基金/b股 2股票
+		r.Date = header.Timestamp
+		switch r.Typ { //2 指数 4 债券（国债/可转债/）3 ETF/基金/b股 2股票
+		case 3:
 			r.Scaling = 1000
-			fmt.Printf("%s:%d: Code=%s Name=%s PrevClose=%.3f Pinyin=%s typ:%d scaling:%f\n", mkt, i, r.Code, r.Name, r.PrevClose, r.NamePinyin, r.Typ, r.Scaling)
-		} else if r.Typ == 4 { //2 指数 4 债券（国债/可转债/）3 ETF/基金/b股 2股票
+		case 4:
 			r.Scaling = 10000
-			fmt.Printf("%s:%d: Code=%s Name=%s PrevClose=%.3f Pinyin=%s typ:%d scaling:%f\n", mkt, i, r.Code, r.Name, r.PrevClose, r.NamePinyin, r.Typ, r.Scaling)
-		} else {
+		default:
 			r.Scaling = 100
-			fmt.Printf("%s:%d: Code=%s Name=%s PrevClose=%.2f Pinyin=%s typ:%d scaling:%f\n", mkt, i, r.Code, r.Name, r.PrevClose, r.NamePinyin, r.Typ, r.Scaling)
 		}
 
 		if cb != nil {
@@ -156,4 +158,5 @@ func readTnf(mkt, path string, cb func(*StockRecord, string)) {
 			cb(r, subtype)
 		}
 	}
+	return nil
 }
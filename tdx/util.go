@@ -64,6 +64,8 @@ func parseCode(mkt, code string) string {
 			return "reits"
 		} else if sec >= 200 && sec <= 209 {
 			return "bshare"
+		} else if sec >= 395 && sec <= 399 {
+			return "index"
 		} else if sec >= 970 {
 			return "index"
 		} else {
@@ -101,6 +103,31 @@ func parseCode(mkt, code string) string {
 	}
 }
 
+// PriceScale 返回通达信整数价格的除数：基金、债券、可转债、REITs 和沪市 B 股为 3 位小数，其余为 2 位
+func PriceScale(symbol string) float64 {
+	if len(symbol) < 3 {
+		return 100
+	}
+	mkt, code := symbol[:2], symbol[2:]
+	switch parseCode(mkt, code) {
+	case "etf", "lof", "fund", "reits", "kzz", "bond":
+		return 1000
+	case "bshare":
+		if mkt == "sh" {
+			return 1000
+		}
+	}
+	return 100
+}
+
+// priceDecimals 返回输出 CSV 时价格保留的小数位数
+func priceDecimals(symbol string) int {
+	if PriceScale(symbol) == 1000 {
+		return 3
+	}
+	return 2
+}
+
 func ParseFileName(n string) (string, string, string) {
 	mkt := ""
 	if strings.HasPrefix(n, "gpsz") {
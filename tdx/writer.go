@@ -0,0 +1,121 @@
+package tdx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"path/filepath"
+
+	"github.com/jing2uo/tdx2db/model"
+)
+
+// VipdocPath 返回证券在 vipdoc 目录下的文件路径，与客户端目录结构一致：
+// .day 在 lday，.01/.lc1 在 minline，.5/.lc5 在 fzline
+func VipdocPath(root, symbol, suffix string) (string, error) {
+	if len(symbol) < 3 {
+		return "", fmt.Errorf("invalid symbol %q", symbol)
+	}
+	mkt := symbol[:2]
+	if mkt != "sh" && mkt != "sz" && mkt != "bj" {
+		return "", fmt.Errorf("invalid market in symbol %q", symbol)
+	}
+
+	var dir string
+	switch suffix {
+	case ".day":
+		dir = "lday"
+	case ".01", ".lc1":
+		dir = "minline"
+	case ".5", ".lc5":
+		dir = "fzline"
+	default:
+		return "", fmt.Errorf("unsupported suffix %s", suffix)
+	}
+	return filepath.Join(root, mkt, dir, symbol+suffix), nil
+}
+
+// EncodeDayRecord 将日线编码为 32 字节整数价格 .day 记录，价格按 PriceScale 放大
+func EncodeDayRecord(r DayKlineRecord) ([]byte, error) {
+	y, m, d := r.Date.Date()
+	scale := PriceScale(r.Symbol)
+
+	var record model.DayfileRecord
+	var err error
+	record.Date = uint32(y*10000 + int(m)*100 + d)
+	if record.Open, err = encodePrice(r.Open, scale); err != nil {
+		return nil, err
+	}
+	if record.High, err = encodePrice(r.High, scale); err != nil {
+		return nil, err
+	}
+	if record.Low, err = encodePrice(r.Low, scale); err != nil {
+		return nil, err
+	}
+	if record.Close, err = encodePrice(r.Close, scale); err != nil {
+		return nil, err
+	}
+	record.Amount = float32(r.Amount)
+	if record.Volume, err = encodeVolume(r.Volume); err != nil {
+		return nil, err
+	}
+
+	return encodeRecord(record)
+}
+
+// EncodeMinRecord 将分钟线编码为 32 字节整数价格 .01/.5 记录
+func EncodeMinRecord(r MinKlineRecord) ([]byte, error) {
+	y, m, d := r.Datetime.Date()
+	if y < 2004 || y > 2035 {
+		return nil, fmt.Errorf("year %d out of range for minute record", y)
+	}
+	scale := PriceScale(r.Symbol)
+
+	var record model.MinfileRecord
+	var err error
+	record.DateRaw = uint16((y-2004)*2048 + int(m)*100 + d)
+	record.TimeRaw = uint16(r.Datetime.Hour()*60 + r.Datetime.Minute())
+	if record.Open, err = encodePrice(r.Open, scale); err != nil {
+		return nil, err
+	}
+	if record.High, err = encodePrice(r.High, scale); err != nil {
+		return nil, err
+	}
+	if record.Low, err = encodePrice(r.Low, scale); err != nil {
+		return nil, err
+	}
+	if record.Close, err = encodePrice(r.Close, scale); err != nil {
+		return nil, err
+	}
+	record.Amount = float32(r.Amount)
+	if record.Volume, err = encodeVolume(r.Volume); err != nil {
+		return nil, err
+	}
+
+	return encodeRecord(record)
+}
+
+func encodeRecord(record any) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, recordSize))
+	if err := binary.Write(buf, binary.LittleEndian, record); err != nil {
+		return nil, fmt.Errorf("binary write failed: %w", err)
+	}
+	//末尾 4 字节保留
+	buf.Write(make([]byte, recordSize-buf.Len()))
+	return buf.Bytes(), nil
+}
+
+func encodePrice(v, scale float64) (uint32, error) {
+	p := math.Round(v * scale)
+	if p < 0 || p > math.MaxUint32 || math.IsNaN(p) {
+		return 0, fmt.Errorf("price %v out of range", v)
+	}
+	return uint32(p), nil
+}
+
+func encodeVolume(v int64) (uint32, error) {
+	if v < 0 || v > math.MaxUint32 {
+		return 0, fmt.Errorf("volume %d out of range", v)
+	}
+	return uint32(v), nil
+}
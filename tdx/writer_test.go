@@ -0,0 +1,63 @@
+package tdx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEncodeDayRecordRoundTrip(t *testing.T) {
+	date := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	for _, want := range []DayKlineRecord{
+		{Symbol: "sz000001", Open: 10.23, High: 10.52, Low: 9.87, Close: 10.01, Amount: 1234567.5, Volume: 98765, Date: date},
+		// ETF 价格 3 位小数，按 1000 放大
+		{Symbol: "sh510300", Open: 3.512, High: 3.547, Low: 3.498, Close: 3.531, Amount: 7654321.5, Volume: 4321000, Date: date},
+	} {
+		b, err := EncodeDayRecord(want)
+		if err != nil {
+			t.Fatalf("%s: %v", want.Symbol, err)
+		}
+		if len(b) != recordSize {
+			t.Fatalf("%s: record size %d, want %d", want.Symbol, len(b), recordSize)
+		}
+		got, err := processDayRecordValue(b, want.Symbol)
+		if err != nil {
+			t.Fatalf("%s: %v", want.Symbol, err)
+		}
+		if got != want {
+			t.Errorf("round trip = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestEncodeMinRecordRoundTrip(t *testing.T) {
+	datetime := time.Date(2024, 5, 6, 14, 55, 0, 0, time.UTC)
+	for _, want := range []MinKlineRecord{
+		{Symbol: "sz000001", Open: 10.23, High: 10.52, Low: 9.87, Close: 10.01, Amount: 1234567.5, Volume: 98765, Datetime: datetime},
+		{Symbol: "sh510300", Open: 3.512, High: 3.547, Low: 3.498, Close: 3.531, Amount: 7654321.5, Volume: 4321000, Datetime: datetime},
+	} {
+		b, err := EncodeMinRecord(want)
+		if err != nil {
+			t.Fatalf("%s: %v", want.Symbol, err)
+		}
+		got, err := processMinRecordValue(b, want.Symbol)
+		if err != nil {
+			t.Fatalf("%s: %v", want.Symbol, err)
+		}
+		if got != want {
+			t.Errorf("round trip = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestEncodeRecordRejectsOutOfRange(t *testing.T) {
+	date := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	if _, err := EncodeDayRecord(DayKlineRecord{Symbol: "sz000001", Open: -1, Date: date}); err == nil {
+		t.Error("negative price: want error")
+	}
+	if _, err := EncodeDayRecord(DayKlineRecord{Symbol: "sz000001", Volume: -1, Date: date}); err == nil {
+		t.Error("negative volume: want error")
+	}
+	if _, err := EncodeMinRecord(MinKlineRecord{Symbol: "sz000001", Datetime: time.Date(2003, 12, 31, 9, 31, 0, 0, time.UTC)}); err == nil {
+		t.Error("year 2003: want error")
+	}
+}